@@ -0,0 +1,361 @@
+package svm
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// The `ABI` type markers.
+//
+// Each encoded value is preceded by a single marker byte.
+// The lower 4 bits select the type family while the upper 4 bits
+// select the variant within it (e.g the number of bytes used for an integer's payload).
+const (
+	abiBoolFalse byte = 0b_0000_0000
+	abiBoolTrue  byte = 0b_0001_0000
+	abiNone      byte = 0b_0010_0000
+	abiUnit      byte = 0b_0011_0000
+	abiAddress   byte = 0b_0100_0000
+
+	abiArray  byte = 0b_0000_0001
+	abiAmount byte = 0b_0000_0010
+	abiI8     byte = 0b_0000_0011
+	abiU8     byte = 0b_0001_0011
+	abiI16    byte = 0b_0000_0100
+	abiI32    byte = 0b_0000_0101
+	abiI64    byte = 0b_0000_0110
+)
+
+// The maximum number of elements an `ABI` array may hold.
+const AbiMaxArrayLength int = 10
+
+// Represents the `ABI` `()` value.
+type Unit struct{}
+
+var errCorruptedAbi = errors.New("Received a corrupted ABI-encoded value")
+
+// Encodes the given values using the `SVM ABI` (the binary form of `calldata`, `verifydata` and `returndata`).
+//
+// Supported Golang types (and their `ABI` counterparts):
+//
+// * `nil` - `None`
+// * `Unit` - `()`
+// * `bool` - `bool`
+// * `Address` and `TemplateAddr` - `Address`
+// * `Amount` - `Amount`
+// * `int8`, `uint8`, `int16`, `uint16`, `int32`, `uint32`, `int64` and `uint64` - the matching integer type.
+// * `[]interface{}` - an array of up to `AbiMaxArrayLength` elements (of the above types).
+//
+// Returns `(nil, error)` when a value of an unsupported type is given.
+func EncodeAbi(values ...interface{}) ([]byte, error) {
+	bytes := []byte{}
+	for _, value := range values {
+		var err error
+		if bytes, err = encodeAbiValue(bytes, value); err != nil {
+			return nil, err
+		}
+	}
+	return bytes, nil
+}
+
+// Decodes a sequence of `SVM ABI` encoded values.
+//
+// Each value is decoded into the Golang type documented under `EncodeAbi`.
+// Returns `(nil, error)` when `bytes` isn't a well-formed `ABI` encoding.
+func DecodeAbi(bytes []byte) ([]interface{}, error) {
+	values := []interface{}{}
+	for len(bytes) > 0 {
+		value, rest, err := decodeAbiValue(bytes)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		bytes = rest
+	}
+	return values, nil
+}
+
+func encodeAbiValue(bytes []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(bytes, abiNone), nil
+	case Unit:
+		return append(bytes, abiUnit), nil
+	case bool:
+		if v {
+			return append(bytes, abiBoolTrue), nil
+		}
+		return append(bytes, abiBoolFalse), nil
+	case Address:
+		bytes = append(bytes, abiAddress)
+		return append(bytes, v[:]...), nil
+	case TemplateAddr:
+		bytes = append(bytes, abiAddress)
+		return append(bytes, v[:]...), nil
+	case Amount:
+		return encodeAbiUnsigned(bytes, abiAmount, 0, uint64(v)), nil
+	case int8:
+		return append(bytes, abiI8, byte(v)), nil
+	case uint8:
+		return append(bytes, abiU8, v), nil
+	case int16:
+		return encodeAbiSigned(bytes, abiI16, int64(v)), nil
+	case uint16:
+		return encodeAbiUnsigned(bytes, abiI16, 2, uint64(v)), nil
+	case int32:
+		return encodeAbiSigned(bytes, abiI32, int64(v)), nil
+	case uint32:
+		return encodeAbiUnsigned(bytes, abiI32, 4, uint64(v)), nil
+	case int64:
+		return encodeAbiSigned(bytes, abiI64, v), nil
+	case uint64:
+		return encodeAbiUnsigned(bytes, abiI64, 8, v), nil
+	case []interface{}:
+		if len(v) > AbiMaxArrayLength {
+			return nil, errors.New("ABI array is too long")
+		}
+		bytes = append(bytes, abiArray|byte(len(v))<<4)
+		for _, elem := range v {
+			var err error
+			if bytes, err = encodeAbiValue(bytes, elem); err != nil {
+				return nil, err
+			}
+		}
+		return bytes, nil
+	default:
+		return nil, errors.New("unsupported ABI type")
+	}
+}
+
+// Encodes `value` using the minimal number of bytes.
+// The number of bytes used (minus one) is added to the variant stored under the marker upper bits.
+func encodeAbiUnsigned(bytes []byte, family byte, variant byte, value uint64) []byte {
+	size := 1
+	for size < 8 && value >= 1<<(8*size) {
+		size++
+	}
+
+	bytes = append(bytes, family|(variant+byte(size-1))<<4)
+	return appendBigEndian(bytes, value, size)
+}
+
+// Encodes `value` (in two's complement) using the minimal number of bytes.
+func encodeAbiSigned(bytes []byte, family byte, value int64) []byte {
+	size := 1
+	for size < 8 && (value < -(1<<(8*size-1)) || value >= 1<<(8*size-1)) {
+		size++
+	}
+
+	bytes = append(bytes, family|byte(size-1)<<4)
+	return appendBigEndian(bytes, uint64(value), size)
+}
+
+func appendBigEndian(bytes []byte, value uint64, size int) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], value)
+	return append(bytes, buf[8-size:]...)
+}
+
+func decodeAbiValue(bytes []byte) (interface{}, []byte, error) {
+	if len(bytes) == 0 {
+		return nil, nil, errCorruptedAbi
+	}
+
+	marker := bytes[0]
+	variant := int(marker >> 4)
+	bytes = bytes[1:]
+
+	switch marker & 0x0F {
+	case abiBoolFalse:
+		switch marker {
+		case abiBoolFalse:
+			return false, bytes, nil
+		case abiBoolTrue:
+			return true, bytes, nil
+		case abiNone:
+			return nil, bytes, nil
+		case abiUnit:
+			return Unit{}, bytes, nil
+		case abiAddress:
+			if len(bytes) < AddressLength {
+				return nil, nil, errCorruptedAbi
+			}
+			addr, bytes := decodeAddress(bytes)
+			return Address(addr), bytes, nil
+		}
+	case abiArray:
+		if variant > AbiMaxArrayLength {
+			return nil, nil, errCorruptedAbi
+		}
+		array := make([]interface{}, variant)
+		for i := range array {
+			elem, rest, err := decodeAbiValue(bytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			array[i] = elem
+			bytes = rest
+		}
+		return array, bytes, nil
+	case abiAmount:
+		if variant < 8 {
+			value, bytes, err := decodeBigEndian(bytes, variant+1)
+			return Amount(value), bytes, err
+		}
+	case abiI8:
+		if len(bytes) < 1 {
+			return nil, nil, errCorruptedAbi
+		}
+		switch marker {
+		case abiI8:
+			return int8(bytes[0]), bytes[1:], nil
+		case abiU8:
+			return uint8(bytes[0]), bytes[1:], nil
+		}
+	case abiI16:
+		if variant < 2 {
+			value, bytes, err := decodeSigned(bytes, variant+1)
+			return int16(value), bytes, err
+		} else if variant < 4 {
+			value, bytes, err := decodeBigEndian(bytes, variant-1)
+			return uint16(value), bytes, err
+		}
+	case abiI32:
+		if variant < 4 {
+			value, bytes, err := decodeSigned(bytes, variant+1)
+			return int32(value), bytes, err
+		} else if variant < 8 {
+			value, bytes, err := decodeBigEndian(bytes, variant-3)
+			return uint32(value), bytes, err
+		}
+	case abiI64:
+		if variant < 8 {
+			value, bytes, err := decodeSigned(bytes, variant+1)
+			return value, bytes, err
+		}
+		value, bytes, err := decodeBigEndian(bytes, variant-7)
+		return value, bytes, err
+	}
+
+	return nil, nil, errors.New("unknown ABI type marker")
+}
+
+func decodeBigEndian(bytes []byte, size int) (uint64, []byte, error) {
+	if len(bytes) < size {
+		return 0, nil, errCorruptedAbi
+	}
+
+	value := uint64(0)
+	for _, b := range bytes[:size] {
+		value = value<<8 | uint64(b)
+	}
+	return value, bytes[size:], nil
+}
+
+func decodeSigned(bytes []byte, size int) (int64, []byte, error) {
+	value, bytes, err := decodeBigEndian(bytes, size)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// sign-extending the `size` bytes read
+	shift := uint(64 - 8*size)
+	return int64(value<<shift) >> shift, bytes, nil
+}
+
+// Holds a single decoded `ABI` value.
+//
+// The underlying Golang value is one of the types documented under `EncodeAbi`.
+type Value struct {
+	raw interface{}
+}
+
+// Decodes a sequence of `SVM ABI` encoded values into `Value`s.
+//
+// Returns `(nil, error)` when `bytes` isn't a well-formed `ABI` encoding.
+func DecodeValues(bytes []byte) ([]Value, error) {
+	raws, err := DecodeAbi(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return toValues(raws), nil
+}
+
+func toValues(raws []interface{}) []Value {
+	values := make([]Value, len(raws))
+	for i, raw := range raws {
+		values[i] = Value{raw: raw}
+	}
+	return values
+}
+
+// Returns the underlying Golang value.
+func (v Value) Interface() interface{} {
+	return v.raw
+}
+
+func (v Value) IsNone() bool {
+	return v.raw == nil
+}
+
+func (v Value) AsBool() (bool, error) {
+	if b, ok := v.raw.(bool); ok {
+		return b, nil
+	}
+	return false, errors.New("ABI value isn't a `bool`")
+}
+
+func (v Value) AsAddress() (Address, error) {
+	if addr, ok := v.raw.(Address); ok {
+		return addr, nil
+	}
+	return Address{}, errors.New("ABI value isn't an `Address`")
+}
+
+func (v Value) AsAmount() (Amount, error) {
+	if amount, ok := v.raw.(Amount); ok {
+		return amount, nil
+	}
+	return Amount(0), errors.New("ABI value isn't an `Amount`")
+}
+
+// Returns the value of any unsigned integer (or an `Amount`) widened to `uint64`.
+func (v Value) AsUint64() (uint64, error) {
+	switch n := v.raw.(type) {
+	case uint8:
+		return uint64(n), nil
+	case uint16:
+		return uint64(n), nil
+	case uint32:
+		return uint64(n), nil
+	case uint64:
+		return n, nil
+	case Amount:
+		return uint64(n), nil
+	default:
+		return 0, errors.New("ABI value isn't an unsigned integer")
+	}
+}
+
+// Returns the value of any signed integer widened to `int64`.
+func (v Value) AsInt64() (int64, error) {
+	switch n := v.raw.(type) {
+	case int8:
+		return int64(n), nil
+	case int16:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	default:
+		return 0, errors.New("ABI value isn't a signed integer")
+	}
+}
+
+func (v Value) AsArray() ([]Value, error) {
+	if array, ok := v.raw.([]interface{}); ok {
+		return toValues(array), nil
+	}
+	return nil, errors.New("ABI value isn't an array")
+}
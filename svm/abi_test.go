@@ -0,0 +1,196 @@
+package svm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeAbiAddress(t *testing.T) {
+	addr := Address{0x10, 0x20, 0x30}
+
+	bytes, err := EncodeAbi(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, append([]byte{0x40}, addr[:]...), bytes)
+}
+
+func TestEncodeAbiMinimalIntegers(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected []byte
+	}{
+		{uint8(0xAB), []byte{0x13, 0xAB}},
+		{int8(-1), []byte{0x03, 0xFF}},
+		{uint16(0), []byte{0x24, 0x00}},
+		{uint16(0x0102), []byte{0x34, 0x01, 0x02}},
+		{int16(-128), []byte{0x04, 0x80}},
+		{int16(128), []byte{0x14, 0x00, 0x80}},
+		{uint32(0x010203), []byte{0x65, 0x01, 0x02, 0x03}},
+		{int32(-129), []byte{0x15, 0xFF, 0x7F}},
+		{uint64(math.MaxUint64), []byte{0xF6, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{int64(1), []byte{0x06, 0x01}},
+		{Amount(0x0100), []byte{0x12, 0x01, 0x00}},
+	}
+
+	for _, c := range cases {
+		bytes, err := EncodeAbi(c.value)
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, bytes, "%T(%v)", c.value, c.value)
+	}
+}
+
+func TestEncodeDecodeAbi(t *testing.T) {
+	expected := []interface{}{
+		nil,
+		Unit{},
+		true,
+		false,
+		Address{0xAA, 0xBB},
+		Amount(0),
+		Amount(math.MaxUint64),
+		int8(math.MinInt8),
+		uint8(math.MaxUint8),
+		int16(math.MinInt16),
+		int16(math.MaxInt16),
+		uint16(math.MaxUint16),
+		int32(math.MinInt32),
+		int32(-1),
+		uint32(math.MaxUint32),
+		int64(math.MinInt64),
+		int64(math.MaxInt64),
+		uint64(0),
+		uint64(1 << 40),
+		[]interface{}{},
+		[]interface{}{Address{0x01}, Address{0x02}},
+		[]interface{}{[]interface{}{true}, []interface{}{uint32(7), nil}},
+	}
+
+	bytes, err := EncodeAbi(expected...)
+	assert.Nil(t, err)
+
+	actual, err := DecodeAbi(bytes)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestEncodeAbiTemplateAddr(t *testing.T) {
+	bytes, err := EncodeAbi(TemplateAddr{0xCC})
+	assert.Nil(t, err)
+
+	actual, err := DecodeAbi(bytes)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{Address{0xCC}}, actual)
+}
+
+func TestEncodeAbiUnsupported(t *testing.T) {
+	_, err := EncodeAbi(10)
+	assert.NotNil(t, err)
+
+	_, err = EncodeAbi("string")
+	assert.NotNil(t, err)
+
+	_, err = EncodeAbi(make([]interface{}, AbiMaxArrayLength+1))
+	assert.NotNil(t, err)
+}
+
+func TestDecodeAbiCorrupted(t *testing.T) {
+	bytes, err := EncodeAbi(Address{0xAA}, uint64(1<<40), []interface{}{true, false})
+	assert.Nil(t, err)
+
+	for n := 1; n < len(bytes); n++ {
+		if n == 1+AddressLength || n == 1+AddressLength+7 {
+			continue
+		}
+		_, err := DecodeAbi(bytes[:n])
+		assert.NotNil(t, err, "truncated at %d", n)
+	}
+
+	_, err = DecodeAbi([]byte{0x50})
+	assert.NotNil(t, err)
+
+	_, err = DecodeAbi([]byte{0xB1})
+	assert.NotNil(t, err)
+}
+
+func TestValueAccessors(t *testing.T) {
+	bytes, err := EncodeAbi(Address{0xAA}, true, uint16(300), int32(-5), Amount(10), nil, []interface{}{uint8(1), uint8(2)})
+	assert.Nil(t, err)
+
+	values, err := DecodeValues(bytes)
+	assert.Nil(t, err)
+	assert.Len(t, values, 7)
+
+	addr, err := values[0].AsAddress()
+	assert.Nil(t, err)
+	assert.Equal(t, Address{0xAA}, addr)
+
+	b, err := values[1].AsBool()
+	assert.Nil(t, err)
+	assert.True(t, b)
+
+	u, err := values[2].AsUint64()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(300), u)
+
+	i, err := values[3].AsInt64()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-5), i)
+
+	amount, err := values[4].AsAmount()
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(10), amount)
+
+	u, err = values[4].AsUint64()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), u)
+
+	assert.True(t, values[5].IsNone())
+
+	array, err := values[6].AsArray()
+	assert.Nil(t, err)
+	assert.Len(t, array, 2)
+	u, err = array[1].AsUint64()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), u)
+}
+
+func TestValueAccessorsMismatch(t *testing.T) {
+	values, err := DecodeValues([]byte{0x10})
+	assert.Nil(t, err)
+
+	_, err = values[0].AsAddress()
+	assert.NotNil(t, err)
+	_, err = values[0].AsUint64()
+	assert.NotNil(t, err)
+	_, err = values[0].AsInt64()
+	assert.NotNil(t, err)
+	_, err = values[0].AsAmount()
+	assert.NotNil(t, err)
+	_, err = values[0].AsArray()
+	assert.NotNil(t, err)
+	assert.False(t, values[0].IsNone())
+}
+
+func TestReceiptReturns(t *testing.T) {
+	returndata, err := EncodeAbi(Address{0xAB})
+	assert.Nil(t, err)
+
+	receipt := &CallReceipt{Success: true, ReturnData: returndata}
+	values, err := receipt.Returns()
+	assert.Nil(t, err)
+	assert.Len(t, values, 1)
+
+	addr, err := values[0].AsAddress()
+	assert.Nil(t, err)
+	assert.Equal(t, Address{0xAB}, addr)
+
+	spawnReceipt := &SpawnReceipt{Success: true, ReturnData: []byte{0x40, 0x01}}
+	_, err = spawnReceipt.Returns()
+	assert.NotNil(t, err)
+
+	spawnReceipt.ReturnData = []byte{}
+	values, err = spawnReceipt.Returns()
+	assert.Nil(t, err)
+	assert.Len(t, values, 0)
+}
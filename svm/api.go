@@ -1,3 +1,5 @@
+// +build cgo,!nosvm
+
 package svm
 
 // #include "svm.h"
@@ -5,8 +7,9 @@ package svm
 import "C"
 import (
 	"errors"
-	"log"
+	"io"
 	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -17,11 +20,17 @@ type svmParams struct {
 	ctxPtr *C.uchar
 }
 
+var _ VM = (*Runtime)(nil)
+var _ io.Closer = (*Runtime)(nil)
+
 var initialized = false
 var initializedGuard = sync.Mutex{}
 
 // Allows for creating new SVM runtime instances via NewRuntime.
-type API struct{}
+type API struct {
+	metrics Metrics
+	logger  Logger
+}
 
 // Init is the entry point for interacting with SVM. It runs SVM initialization
 // logic; it is fully thread-safe and idempotent.
@@ -61,23 +70,54 @@ func AssertInitialized() {
 // * `path` 	- the path under which `SVM Global State` will store its content.
 //   This is relevant only when `isMemory=false` (otherwise the `path` value will be ignored).
 //
+// # Notes
+//
+// When `inMemory=false`, the index of the committed layers `State`s (see `StateAt`) is persisted
+// in a sidecar file named `path` followed by `.state_roots` (i.e next to the `SVM` storage directory, not inside it).
+// The file is only a cache of what `SVM` already committed, thus it's safe to delete it.
+// On startup it's reconciled with the last layer committed by `SVM`: records of layers beyond it are dropped,
+// a missing record of it is appended, and when the file is missing, corrupted or mismatches `SVM`,
+// the index restarts from the last committed layer (i.e the `State`s of the previous layers are no longer known).
+//
 // On success returns it and the `error` is set to `nil`.
 // On failure returns `(nil, error).
-func (*API) NewRuntime(inMemory bool, path string) (*Runtime, error) {
-	rt := &Runtime{}
+func (api *API) NewRuntime(inMemory bool, path string) (*Runtime, error) {
+	rt := &Runtime{metrics: metricsOrNoop(api.metrics), logger: loggerOrNoop(api.logger)}
 
 	var res C.svm_result_t
 	if inMemory {
 		res = C.svm_runtime_create(&rt.raw, nil, 0)
 	} else {
+		if path == "" {
+			return nil, errors.New("`path` cannot be empty")
+		}
 		bytes := ([]byte)(path)
-		rawPath := (*C.uchar)(unsafe.Pointer(&bytes))
+		rawPath := (*C.uchar)(unsafe.Pointer(&bytes[0]))
 		pathLen := (C.uint32_t)(uint32(len(path)))
 		res = C.svm_runtime_create(&rt.raw, rawPath, pathLen)
 	}
-	_, err := copySvmResult(res)
+	if _, err := copySvmResult(res); err != nil {
+		return rt, err
+	}
+	trackRuntime(rt)
+	rt.metrics.RuntimeCreated()
 
-	return rt, err
+	// a persisted `Runtime` resumes from its last committed layer
+	layer, state, err := rt.layerInfo()
+	if err != nil {
+		rt.Close()
+		return nil, err
+	}
+	rt.layers.last = Layer(layer)
+
+	if inMemory {
+		rt.index = newStateIndex(Layer(layer), state)
+	} else if rt.index, err = loadStateIndex(stateIndexPath(path), Layer(layer), state); err != nil {
+		rt.Close()
+		return nil, err
+	}
+
+	return rt, nil
 }
 
 func (*API) RuntimesCount() int {
@@ -92,21 +132,102 @@ func (*API) ReceiptsCount() int {
 	// return int(count)
 }
 
-// Releases the SVM Runtime
+// Releases the SVM Runtime (same as `Close`).
 func (rt *Runtime) Destroy() {
+	rt.Close()
+}
+
+// Releases the SVM Runtime.
+//
+// Waits for the operations in progress to complete. Any further usage of the `Runtime` returns `ErrRuntimeClosed`,
+// and calling `Close` again is a no-op.
+func (rt *Runtime) Close() error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
 	if rt.raw != nil {
 		C.svm_runtime_destroy(rt.raw)
+		rt.raw = nil
+		untrackRuntime(rt)
+		rt.metrics.RuntimeClosed()
+	}
+	return nil
+}
+
+// Replaces the `Metrics` of the `Runtime` (see `API.SetMetrics`).
+//
+// From now on, the `Runtime` is accounted as a live one by `metrics` (instead of by the previous `Metrics`).
+func (rt *Runtime) SetMetrics(metrics Metrics) error {
+	if err := rt.lock(); err != nil {
+		return err
 	}
+	defer rt.mu.Unlock()
+
+	rt.metrics.RuntimeClosed()
+	rt.metrics = metricsOrNoop(metrics)
+	rt.metrics.RuntimeCreated()
+	return nil
+}
+
+// Replaces the `Logger` of the `Runtime` (see `API.SetLogger`). A `nil` `logger` silences the `Runtime`.
+func (rt *Runtime) SetLogger(logger Logger) error {
+	if err := rt.lock(); err != nil {
+		return err
+	}
+	defer rt.mu.Unlock()
+
+	rt.logger = loggerOrNoop(logger)
+	return nil
+}
+
+// Reports a completed `op` to the `Metrics` and the `Logger` of the `Runtime`.
+func (rt *Runtime) report(op Op, start time.Time, env *Envelope, ctx *Context, receipt interface{}, err error) {
+	observeOp(rt.metrics, op, start, receipt, err)
+	logOp(rt.logger, op, env, ctx, receipt, err)
+}
+
+// Acquires the `Runtime` exclusively (for operations mutating it).
+// Returns `ErrRuntimeClosed` (without holding the lock) when the `Runtime` has been destroyed.
+func (rt *Runtime) lock() error {
+	rt.mu.Lock()
+	if rt.raw == nil {
+		rt.mu.Unlock()
+		return ErrRuntimeClosed
+	}
+	return nil
+}
+
+// Acquires the `Runtime` for a read-only operation (which may run concurrently with other read-only ones).
+//
+// Only operations reading Go-side state may use it: `SVM` doesn't document its C-API as safe for concurrent calls
+// on the same raw runtime, so any call into `SVM` (even a read-only one) holds the `Runtime` exclusively.
+// Returns `ErrRuntimeClosed` (without holding the lock) when the `Runtime` has been destroyed.
+func (rt *Runtime) rlock() error {
+	rt.mu.RLock()
+	if rt.raw == nil {
+		rt.mu.RUnlock()
+		return ErrRuntimeClosed
+	}
+	return nil
 }
 
 // Validates the `Deploy Message` given in its binary form.
 //
 // Returns `(true, nil)` when the `msg` is syntactically valid,
 // and `(false, error)` otherwise.  In that case `error` will have non-`nil` value.
+// An invalid `msg` results in a `*ValidateError`.
 func (rt *Runtime) ValidateDeploy(msg []byte) (bool, error) {
-	return runValidation(msg, func(rawMsg *C.uchar, msgLen C.uint32_t) C.svm_result_t {
+	if err := rt.lock(); err != nil {
+		return false, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	valid, err := runValidation(msg, func(rawMsg *C.uchar, msgLen C.uint32_t) C.svm_result_t {
 		return C.svm_validate_deploy(rt.raw, rawMsg, msgLen)
 	})
+	rt.report(OpValidateDeploy, start, nil, nil, nil, err)
+	return valid, err
 }
 
 // Executes a `Deploy` transaction and returns back a receipt.
@@ -120,10 +241,17 @@ func (rt *Runtime) ValidateDeploy(msg []byte) (bool, error) {
 // # Notes
 //
 // A Receipt is always being returned, even if there was an internal error inside SVM.
+// Returns `ErrLayerNotOpen` when called outside an open layer,
+// and a `*LayerError` when `ctx.Layer` isn't the open layer.
 func (rt *Runtime) Deploy(env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error) {
-	object, err := runAction(env, msg, ctx, func(params *svmParams) C.svm_result_t {
-		return C.svm_deploy(rt.raw, params.envPtr, params.msgPtr, params.msgLen, params.ctxPtr)
-	})
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	object, err := rt.execute(env, msg, ctx, rt.deployAction)
+	rt.report(OpDeploy, start, env, ctx, object, err)
 
 	if err != nil {
 		return nil, err
@@ -136,10 +264,19 @@ func (rt *Runtime) Deploy(env *Envelope, msg []byte, ctx *Context) (*DeployRecei
 //
 // Returns `(true, nil)` when the `msg` is syntactically valid,
 // and `(false, error)` otherwise.  In that case `error` will have non-`nil` value.
+// An invalid `msg` results in a `*ValidateError`.
 func (rt *Runtime) ValidateSpawn(msg []byte) (bool, error) {
-	return runValidation(msg, func(rawMsg *C.uchar, msgLen C.uint32_t) C.svm_result_t {
+	if err := rt.lock(); err != nil {
+		return false, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	valid, err := runValidation(msg, func(rawMsg *C.uchar, msgLen C.uint32_t) C.svm_result_t {
 		return C.svm_validate_spawn(rt.raw, rawMsg, msgLen)
 	})
+	rt.report(OpValidateSpawn, start, nil, nil, nil, err)
+	return valid, err
 }
 
 // Executes a `Spawn` transaction and returns back a receipt.
@@ -154,10 +291,17 @@ func (rt *Runtime) ValidateSpawn(msg []byte) (bool, error) {
 // # Notes
 //
 // A Receipt is always being returned, even if there was an internal error inside SVM.
+// Returns `ErrLayerNotOpen` when called outside an open layer,
+// and a `*LayerError` when `ctx.Layer` isn't the open layer.
 func (rt *Runtime) Spawn(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
-	object, err := runAction(env, msg, ctx, func(params *svmParams) C.svm_result_t {
-		return C.svm_spawn(rt.raw, params.envPtr, params.msgPtr, params.msgLen, params.ctxPtr)
-	})
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	object, err := rt.execute(env, msg, ctx, rt.spawnAction)
+	rt.report(OpSpawn, start, env, ctx, object, err)
 
 	if err != nil {
 		return nil, err
@@ -170,10 +314,19 @@ func (rt *Runtime) Spawn(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt
 //
 // Returns `(true, nil)` when the `msg` is syntactically valid,
 // and `(false, error)` otherwise.  In that case `error` will have non-`nil` value.
+// An invalid `msg` results in a `*ValidateError`.
 func (rt *Runtime) ValidateCall(msg []byte) (bool, error) {
-	return runValidation(msg, func(rawMsg *C.uchar, msgLen C.uint32_t) C.svm_result_t {
+	if err := rt.lock(); err != nil {
+		return false, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	valid, err := runValidation(msg, func(rawMsg *C.uchar, msgLen C.uint32_t) C.svm_result_t {
 		return C.svm_validate_call(rt.raw, rawMsg, msgLen)
 	})
+	rt.report(OpValidateCall, start, nil, nil, nil, err)
+	return valid, err
 }
 
 // Executes a `Call` transaction and returns back a receipt.
@@ -187,10 +340,22 @@ func (rt *Runtime) ValidateCall(msg []byte) (bool, error) {
 // # Notes
 //
 // A Receipt is always being returned, even if there was an internal error inside SVM.
+// Returns `ErrLayerNotOpen` when called outside an open layer,
+// and a `*LayerError` when `ctx.Layer` isn't the open layer.
 func (rt *Runtime) Call(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
-	object, err := runAction(env, msg, ctx, func(params *svmParams) C.svm_result_t {
-		return C.svm_call(rt.raw, params.envPtr, params.msgPtr, params.msgLen, params.ctxPtr)
-	})
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	return rt.callLocked(env, msg, ctx)
+}
+
+// Same as `Call`, while the `Runtime` is already held by the caller.
+func (rt *Runtime) callLocked(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	start := time.Now()
+	object, err := rt.execute(env, msg, ctx, rt.callAction)
+	rt.report(OpCall, start, env, ctx, object, err)
 
 	if err != nil {
 		return nil, err
@@ -214,9 +379,21 @@ func (rt *Runtime) Call(env *Envelope, msg []byte, ctx *Context) (*CallReceipt,
 //
 // A Receipt is always being returned, even if there was an internal error inside SVM.
 func (rt *Runtime) Verify(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	return rt.verifyLocked(env, msg, ctx)
+}
+
+// Same as `Verify`, while the `Runtime` is already held by the caller.
+func (rt *Runtime) verifyLocked(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	start := time.Now()
 	object, err := runAction(env, msg, ctx, func(params *svmParams) C.svm_result_t {
 		return C.svm_verify(rt.raw, params.envPtr, params.msgPtr, params.msgLen, params.ctxPtr)
 	})
+	rt.report(OpVerify, start, env, ctx, object, err)
 
 	if err != nil {
 		return nil, err
@@ -225,37 +402,100 @@ func (rt *Runtime) Verify(env *Envelope, msg []byte, ctx *Context) (*CallReceipt
 	return object.(*CallReceipt), nil
 }
 
+// Executes a `Call` transaction preceded by its `Verify` stage.
+//
+// The `Verify` stage runs first (against the `Template` of the `Principal` Account).
+// Only if it succeeds, the `Call` stage is executed with the `Gas` left (i.e the `GasLimit` minus the `Verify` `GasUsed`).
+// The `Runtime` is held throughout both stages, so no other operation can interleave them.
+//
+// # Params
+//
+// * `env` - the transaction `Envelope`
+// * `msg` - the transaction `Message`
+// * `ctx` - the executed `Context` (the `current layer` etc).
+//
+// # Notes
+//
+// An `error` is returned only when either stage couldn't be executed at all.
+// When the `Call` stage couldn't be executed, the `ExecuteCallReceipt` holding the `Verify` stage is returned along with the `error`.
+func (rt *Runtime) ExecuteCall(env *Envelope, msg []byte, ctx *Context) (*ExecuteCallReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	return executeCall(rt.verifyLocked, rt.callLocked, env, msg, ctx)
+}
+
 // Signaling `SVM` that we are about to start playing a list of transactions under the input `layer` Layer.
 //
 // # Notes
 //
 // * The value of `layer` is expected to equal the last known `committed/rewinded` layer plus one
-//   Any other `layer` given as input will result in an error returned.
+//   Any other `layer` given as input will result in a `*LayerError` returned.
 //
-// * Calling `Open` twice in a row will result in an `error` returned.
+// * Calling `Open` twice in a row will result in `ErrLayerAlreadyOpen` returned.
 func (rt *Runtime) Open(layer Layer) error {
+	if err := rt.lock(); err != nil {
+		return err
+	}
+	defer rt.mu.Unlock()
+
+	if err := rt.layers.open(layer); err != nil {
+		return err
+	}
+
 	res := C.svm_uncommitted_changes(rt.raw)
 	_, err := copySvmResult(res)
 	if err != nil {
+		rt.layers.opened = false
 		return err
 	}
-	log.Print("Ready to play SVM transactions in a new layer.")
+	rt.logger.Log(LogDebug, "layer opened", LogField{LogKeyLayer, layer})
 	return nil
 }
 
 // Rewinds the `SVM Global State` back to the input `layer`.
 //
-// In case there is no such layer to rewind to - returns an `error`.
+// In case there is no such layer to rewind to - returns a `*LayerError`.
+// Rewinding also discards the currently open layer (if any).
 func (rt *Runtime) Rewind(layer Layer) (State, error) {
+	if err := rt.lock(); err != nil {
+		return State{}, err
+	}
+	defer rt.mu.Unlock()
+
+	state, err := rt.rewind(layer)
+	if err != nil {
+		rt.logger.Log(logErrorLevel(err), "layer rewind error", LogField{LogKeyLayer, layer}, LogField{LogKeyError, err})
+		return State{}, err
+	}
+
+	rt.metrics.ObserveRewind(layer)
+	rt.logger.Log(LogInfo, "layer rewinded", LogField{LogKeyLayer, layer}, LogField{LogKeyState, state})
+	return state, nil
+}
+
+func (rt *Runtime) rewind(layer Layer) (State, error) {
+	if err := rt.layers.ensureRewindable(layer); err != nil {
+		return State{}, err
+	}
+
 	res := C.svm_rewind(rt.raw, C.uint64_t(layer))
 	_, err := copySvmResult(res)
 	if err != nil {
 		return State{}, err
 	}
-	state, err := rt.StateHash()
+	rt.layers.rewinded(layer)
+	rt.journal = nil
+
+	_, state, err := rt.layerInfo()
 	if err != nil {
 		return State{}, err
 	}
+	if err := rt.index.rewind(layer, state); err != nil {
+		return State{}, err
+	}
 	return state, nil
 }
 
@@ -273,6 +513,11 @@ func (rt *Runtime) layerInfo() (uint64, State, error) {
 }
 
 func (rt *Runtime) StateHash() (State, error) {
+	if err := rt.lock(); err != nil {
+		return State{}, err
+	}
+	defer rt.mu.Unlock()
+
 	_, state, err := rt.layerInfo()
 	return state, err
 }
@@ -282,22 +527,96 @@ func (rt *Runtime) StateHash() (State, error) {
 // In other words, returns the `layer` associated with the just-committed changes.
 //
 // In case commits fails (for example, persisting to disk failure) - returns `(0, error)`
+// Calling `Commit` without an open layer returns `ErrLayerNotOpen`.
 func (rt *Runtime) Commit() (Layer, State, error) {
+	if err := rt.lock(); err != nil {
+		return Layer(0), State{}, err
+	}
+	defer rt.mu.Unlock()
+
+	layer, state, err := rt.commit()
+	if err != nil {
+		rt.logger.Log(logErrorLevel(err), "layer commit error", LogField{LogKeyLayer, rt.layers.current()}, LogField{LogKeyError, err})
+		return layer, state, err
+	}
+
+	rt.metrics.ObserveCommit(layer)
+	rt.logger.Log(LogInfo, "layer committed", LogField{LogKeyLayer, layer}, LogField{LogKeyState, state})
+	return layer, state, nil
+}
+
+func (rt *Runtime) commit() (Layer, State, error) {
+	if err := rt.layers.ensureCommittable(); err != nil {
+		return Layer(0), State{}, err
+	}
+
 	res := C.svm_commit(rt.raw)
 
 	_, err := copySvmResult(res)
 	if err != nil {
 		return Layer(0), State{}, err
 	}
+	rt.layers.committed()
+	rt.journal = nil
 
 	layer, hash, err := rt.layerInfo()
+	if err != nil {
+		return Layer(layer), State{}, err
+	}
+	if err := rt.index.commit(Layer(layer), hash); err != nil {
+		return Layer(layer), State{}, err
+	}
 	return Layer(layer), hash, nil
 }
 
+// Returns the `State` committed under `layer`.
+//
+// Returns `ErrLayerNotCommitted` when `layer` hasn't been committed (or has been rewinded).
+func (rt *Runtime) StateAt(layer Layer) (State, error) {
+	if err := rt.rlock(); err != nil {
+		return State{}, err
+	}
+	defer rt.mu.RUnlock()
+
+	return rt.index.stateAt(layer)
+}
+
+// Returns the last committed (or rewinded to) layer and its `State`.
+func (rt *Runtime) LastCommitted() (Layer, State, error) {
+	if err := rt.rlock(); err != nil {
+		return Layer(0), State{}, err
+	}
+	defer rt.mu.RUnlock()
+
+	layer := rt.index.last()
+	state, err := rt.index.stateAt(layer)
+	return layer, state, err
+}
+
+// Returns the committed layers (and their `State`s) within `[from, to]`, both inclusive.
+//
+// Layers not known to the index (e.g. committed before a persisted index was created) are omitted.
+func (rt *Runtime) CommittedLayers(from Layer, to Layer) ([]LayerState, error) {
+	if err := rt.rlock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.RUnlock()
+
+	if from > to {
+		return nil, errors.New("`from` cannot exceed `to`")
+	}
+	return rt.index.between(from, to), nil
+}
+
 // Given an `Account Address` - retrieves its most basic information encapuslated within an `Account` struct.
 //
 // Returns a `(nil, error)` in case the requested `Account` doesn't exist.
 func (rt *Runtime) GetAccount(addr Address) (Account, error) {
+	if err := rt.lock(); err != nil {
+		return Account{}, err
+	}
+	defer rt.mu.Unlock()
+
 	var account C.svm_account
 	addrRaw := (*C.uchar)(unsafe.Pointer(&addr[0]))
 
@@ -319,6 +638,22 @@ func (rt *Runtime) GetAccount(addr Address) (Account, error) {
 }
 
 func (rt *Runtime) CreateAccount(account Account) error {
+	if err := rt.lock(); err != nil {
+		return err
+	}
+	defer rt.mu.Unlock()
+
+	if err := rt.createAccount(account); err != nil {
+		return err
+	}
+
+	rt.journal = append(rt.journal, func() error {
+		return rt.createAccount(account)
+	})
+	return nil
+}
+
+func (rt *Runtime) createAccount(account Account) error {
 	res := C.svm_create_genesis_account(
 		rt.raw,
 		(*C.uchar)(unsafe.Pointer(&account.Addr[0])),
@@ -338,6 +673,22 @@ func (rt *Runtime) CreateAccount(account Account) error {
 // * `addr`   - The `Account Address` we want to increase its balance.
 // * `amount` - The `Amount` by which we are going to increase the account's balance.
 func (rt *Runtime) IncreaseBalance(addr Address, amount Amount) error {
+	if err := rt.lock(); err != nil {
+		return err
+	}
+	defer rt.mu.Unlock()
+
+	if err := rt.increaseBalance(addr, amount); err != nil {
+		return err
+	}
+
+	rt.journal = append(rt.journal, func() error {
+		return rt.increaseBalance(addr, amount)
+	})
+	return nil
+}
+
+func (rt *Runtime) increaseBalance(addr Address, amount Amount) error {
 	res := C.svm_increase_balance(
 		rt.raw,
 		(*C.uchar)(unsafe.Pointer(&addr[0])),
@@ -348,23 +699,6 @@ func (rt *Runtime) IncreaseBalance(addr Address, amount Amount) error {
 	return err
 }
 
-func NewEnvelope(principal Address, amount Amount, txNonce TxNonce, gasLimit Gas, gasFee GasFee) *Envelope {
-	return &Envelope{
-		Principal: principal,
-		Amount:    amount,
-		TxNonce:   txNonce,
-		GasLimit:  gasLimit,
-		GasFee:    gasFee,
-	}
-}
-
-func NewContext(layer Layer, txId TxId) *Context {
-	return &Context{
-		Layer: layer,
-		TxId:  txId,
-	}
-}
-
 func toSvmParams(env *Envelope, msg []byte, ctx *Context) *svmParams {
 	envBytes := encodeEnvelope(env)
 	envPtr := (*C.uchar)(unsafe.Pointer(&envBytes[0]))
@@ -384,24 +718,97 @@ func toSvmParams(env *Envelope, msg []byte, ctx *Context) *svmParams {
 }
 
 func copySvmResult(res C.struct_svm_result_t) ([]byte, error) {
+	receipt, rawErr := copySvmResultRaw(res)
+	if rawErr != nil {
+		return nil, errors.New(string(rawErr))
+	}
+	return receipt, nil
+}
+
+// Same as `copySvmResult` but returns the error payload (if any) in its binary form.
+func copySvmResultRaw(res C.struct_svm_result_t) ([]byte, []byte) {
 	size := C.int(res.buf_size)
 
 	receipt := ([]byte)(nil)
-	err := (error)(nil)
+	rawErr := ([]byte)(nil)
 
 	if res.receipt != nil {
 		ptr := unsafe.Pointer(res.receipt)
 		receipt = C.GoBytes(ptr, size)
 	} else if res.error != nil {
 		ptr := unsafe.Pointer(res.error)
-		err = errors.New(string(C.GoBytes(ptr, size)))
+		rawErr = C.GoBytes(ptr, size)
 	}
 
 	C.svm_free_result(res)
-	return receipt, err
+	return receipt, rawErr
 }
 
 type svmAction func(params *svmParams) C.svm_result_t
+
+func (rt *Runtime) deployAction(params *svmParams) C.svm_result_t {
+	return C.svm_deploy(rt.raw, params.envPtr, params.msgPtr, params.msgLen, params.ctxPtr)
+}
+
+func (rt *Runtime) spawnAction(params *svmParams) C.svm_result_t {
+	return C.svm_spawn(rt.raw, params.envPtr, params.msgPtr, params.msgLen, params.ctxPtr)
+}
+
+func (rt *Runtime) callAction(params *svmParams) C.svm_result_t {
+	return C.svm_call(rt.raw, params.envPtr, params.msgPtr, params.msgLen, params.ctxPtr)
+}
+
+// Executes a transaction within the open layer, and records it under the layer journal.
+func (rt *Runtime) execute(env *Envelope, msg []byte, ctx *Context, action svmAction) (interface{}, error) {
+	if err := rt.layers.ensureExecutable(ctx); err != nil {
+		return nil, err
+	}
+
+	object, err := runAction(env, msg, ctx, action)
+	if err != nil {
+		return nil, err
+	}
+
+	envCopy, msgCopy, ctxCopy := *env, append([]byte{}, msg...), *ctx
+	rt.journal = append(rt.journal, func() error {
+		_, err := runAction(&envCopy, msgCopy, &ctxCopy, action)
+		return err
+	})
+	return object, nil
+}
+
+// Discards the uncommitted changes following the first `n` journal entries.
+//
+// Since `SVM` can only rewind to a committed layer, the uncommitted state is restored
+// by rewinding to the last committed layer and replaying the first `n` journal entries.
+// Thus, the cost of a restore grows with the number of transactions applied since the last `Commit`.
+//
+// When the rewind or any replay fails, `SVM` is left with a partially replayed state.
+// The layer is then marked as broken (see `BrokenLayerError`), so that it can't be committed before a `Rewind`.
+func (rt *Runtime) restore(n int) error {
+	if err := rt.replay(n); err != nil {
+		rt.layers.broke(err)
+		return rt.layers.ensureIntact()
+	}
+	return nil
+}
+
+func (rt *Runtime) replay(n int) error {
+	res := C.svm_rewind(rt.raw, C.uint64_t(rt.layers.last))
+	if _, err := copySvmResult(res); err != nil {
+		return err
+	}
+
+	journal := rt.journal[:n]
+	for _, replay := range journal {
+		if err := replay(); err != nil {
+			return err
+		}
+	}
+	rt.journal = journal
+	return nil
+}
+
 type svmValidation func(rawMsg *C.uchar, msgLen C.uint32_t) C.svm_result_t
 
 func runAction(env *Envelope, msg []byte, ctx *Context, action svmAction) (interface{}, error) {
@@ -422,14 +829,17 @@ func runAction(env *Envelope, msg []byte, ctx *Context, action svmAction) (inter
 
 func runValidation(msg []byte, validator svmValidation) (bool, error) {
 	if len(msg) == 0 {
-		return false, errors.New("`msg` cannot be empty")
+		return false, &ValidateError{Message: "`msg` cannot be empty"}
 	}
 
 	rawMsg := (*C.uchar)(unsafe.Pointer(&msg[0]))
 	msgLen := (C.uint32_t)(len(msg))
 
 	res := validator(rawMsg, msgLen)
-	_, err := copySvmResult(res)
+	_, rawErr := copySvmResultRaw(res)
+	if rawErr != nil {
+		return false, decodeValidateError(rawErr)
+	}
 
-	return err == nil, err
+	return true, nil
 }
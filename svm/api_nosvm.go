@@ -0,0 +1,161 @@
+// +build !cgo nosvm
+
+package svm
+
+import (
+	"context"
+	"io"
+)
+
+// This file is compiled instead of `api.go` when `go-svm` is built without `cgo` (or with the `nosvm` build tag).
+// All the types, codecs and receipt decoders remain fully functional,
+// while any attempt to create or use a `Runtime` results in `ErrSvmNotAvailable`.
+
+var _ VM = (*Runtime)(nil)
+var _ io.Closer = (*Runtime)(nil)
+
+// Allows for creating new SVM runtime instances via NewRuntime.
+type API struct {
+	metrics Metrics
+	logger  Logger
+}
+
+// Always returns `ErrSvmNotAvailable` (the SVM library isn't linked into this build).
+func Init() (*API, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+// Asserts that `Init` has already been called.
+//
+// # Panics
+//
+// Always panics since `Init` can't succeed without the SVM library.
+func AssertInitialized() {
+	panic(ErrSvmNotAvailable)
+}
+
+func (*API) NewRuntime(inMemory bool, path string) (*Runtime, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (*API) RuntimesCount() int {
+	return 0
+}
+
+func (*API) ReceiptsCount() int {
+	return 0
+}
+
+func (rt *Runtime) Destroy() {}
+
+func (rt *Runtime) Close() error {
+	return nil
+}
+
+func (rt *Runtime) ValidateDeploy(msg []byte) (bool, error) {
+	return false, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) Deploy(env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) ValidateSpawn(msg []byte) (bool, error) {
+	return false, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) Spawn(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) ValidateCall(msg []byte) (bool, error) {
+	return false, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) Call(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) Verify(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) ExecuteCall(env *Envelope, msg []byte, ctx *Context) (*ExecuteCallReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) Open(layer Layer) error {
+	return ErrSvmNotAvailable
+}
+
+func (rt *Runtime) Rewind(layer Layer) (State, error) {
+	return State{}, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) StateHash() (State, error) {
+	return State{}, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) Commit() (Layer, State, error) {
+	return Layer(0), State{}, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) GetAccount(addr Address) (Account, error) {
+	return Account{}, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) CreateAccount(account Account) error {
+	return ErrSvmNotAvailable
+}
+
+func (rt *Runtime) IncreaseBalance(addr Address, amount Amount) error {
+	return ErrSvmNotAvailable
+}
+
+func (rt *Runtime) StateAt(layer Layer) (State, error) {
+	return State{}, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) LastCommitted() (Layer, State, error) {
+	return Layer(0), State{}, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) CommittedLayers(from Layer, to Layer) ([]LayerState, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) EstimateGas(txType TxType, env *Envelope, msg []byte, ctx *Context) (*GasEstimate, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) SimulateDeploy(env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) SimulateSpawn(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) SimulateCall(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) DeployContext(goCtx context.Context, env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) SpawnContext(goCtx context.Context, env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) CallContext(goCtx context.Context, env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	return nil, ErrSvmNotAvailable
+}
+
+func (rt *Runtime) SetMetrics(metrics Metrics) error {
+	return ErrSvmNotAvailable
+}
+
+func (rt *Runtime) SetLogger(logger Logger) error {
+	return ErrSvmNotAvailable
+}
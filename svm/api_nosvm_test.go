@@ -0,0 +1,56 @@
+// +build !cgo nosvm
+
+package svm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitNotAvailable(t *testing.T) {
+	api, err := Init()
+	assert.Nil(t, api)
+	assert.True(t, errors.Is(err, ErrSvmNotAvailable))
+
+	assert.Panics(t, AssertInitialized)
+}
+
+func TestRuntimeNotAvailable(t *testing.T) {
+	rt, err := (&API{}).NewRuntime(true, "")
+	assert.Nil(t, rt)
+	assert.True(t, errors.Is(err, ErrSvmNotAvailable))
+
+	var vm VM = &Runtime{}
+	defer vm.Destroy()
+
+	_, err = vm.ValidateDeploy(readFile(t, "inputs/template_example.svm"))
+	assert.True(t, errors.Is(err, ErrSvmNotAvailable))
+
+	_, err = vm.Deploy(NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(0), GasFee(0)), []byte{0}, NewContext(Layer(0), TxId{}))
+	assert.True(t, errors.Is(err, ErrSvmNotAvailable))
+
+	_, _, err = vm.Commit()
+	assert.True(t, errors.Is(err, ErrSvmNotAvailable))
+
+	_, err = (&Runtime{}).CallContext(context.Background(), NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(0), GasFee(0)), []byte{0}, NewContext(Layer(0), TxId{}))
+	assert.True(t, errors.Is(err, ErrSvmNotAvailable))
+
+	_, err = vm.GetAccount(Address{})
+	assert.True(t, errors.Is(err, ErrSvmNotAvailable))
+}
+
+func TestRuntimePoolNotAvailable(t *testing.T) {
+	pool, err := (&API{}).NewRuntimePool(2)
+	assert.Nil(t, pool)
+	assert.True(t, errors.Is(err, ErrSvmNotAvailable))
+}
+
+func TestRuntimeCloseNotAvailable(t *testing.T) {
+	rt := &Runtime{}
+	assert.Nil(t, rt.Close())
+	assert.Nil(t, rt.Close())
+	assert.Empty(t, LeakedRuntimes())
+}
@@ -1,18 +1,18 @@
+// +build cgo,!nosvm
+
 package svm
 
 import (
-	"io/ioutil"
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func readFile(t *testing.T, path string) []byte {
-	bytes, err := ioutil.ReadFile(path)
-	assert.Nil(t, err)
-	return bytes
-}
-
 func runtimeSetup(t *testing.T) *Runtime {
 	api, err := Init()
 	assert.Nil(t, err)
@@ -21,6 +21,9 @@ func runtimeSetup(t *testing.T) *Runtime {
 	assert.NotNil(t, rt)
 	assert.Nil(t, err)
 
+	err = rt.Open(Layer(1))
+	assert.Nil(t, err)
+
 	return rt
 }
 
@@ -44,7 +47,7 @@ func NewTestParams() *TestParams {
 		TxId:      TxId{},
 		Gas:       Gas(1000000000),
 		GasFee:    GasFee(0),
-		Layer:     Layer(0),
+		Layer:     Layer(1),
 	}
 }
 
@@ -84,6 +87,21 @@ func call(t *testing.T, rt *Runtime, path string, params *TestParams) (*CallRece
 	return receipt.(*CallReceipt), err
 }
 
+// Deploys `template_example.svm` and spawns the Account targeted by the `inputs/call/*.json.bin` messages (under `layer`).
+func spawnTarget(t *testing.T, rt *Runtime, layer Layer) Address {
+	params := NewTestParams()
+	params.Layer = layer
+
+	deployed, err := deploy(t, rt, "inputs/template_example.svm", params)
+	assert.Nil(t, err)
+	assert.True(t, deployed.Success)
+
+	spawned, err := spawn(t, rt, "inputs/spawn/initialize.json.bin", params)
+	assert.Nil(t, err)
+	assert.True(t, spawned.Success)
+	return spawned.AccountAddr
+}
+
 func TestInitNilErr(t *testing.T) {
 	_, err := Init()
 	assert.Nil(t, err)
@@ -108,6 +126,10 @@ func TestValidateEmptyDeploy(t *testing.T) {
 	ok, err := rt.ValidateDeploy([]byte{})
 	assert.False(t, ok)
 	assert.NotNil(t, err)
+
+	var validateErr *ValidateError
+	assert.True(t, errors.As(err, &validateErr))
+	assert.Equal(t, "`msg` cannot be empty", validateErr.Message)
 }
 
 func TestValidateDeployInvalid(t *testing.T) {
@@ -118,6 +140,9 @@ func TestValidateDeployInvalid(t *testing.T) {
 	valid, err := rt.ValidateDeploy(msg)
 	assert.False(t, valid)
 	assert.NotNil(t, err)
+
+	var validateErr *ValidateError
+	assert.True(t, errors.As(err, &validateErr))
 }
 
 func TestValidateDeployValid(t *testing.T) {
@@ -154,13 +179,14 @@ func TestDeployOutOfGas(t *testing.T) {
 
 	msg := readFile(t, "inputs/template_example.svm")
 	env := NewEnvelope(Address{}, Amount(10), TxNonce{Upper: 0, Lower: 0}, Gas(10), GasFee(0))
-	ctx := NewContext(Layer(0), TxId{})
+	ctx := NewContext(Layer(1), TxId{})
 
 	receipt, err := rt.Deploy(env, msg, ctx)
 	assert.Nil(t, err)
 
 	assert.Equal(t, false, receipt.Success)
 	assert.Equal(t, receipt.Error.Kind, RuntimeErrorKind(OOG))
+	assert.True(t, errors.Is(receipt.Err(), ErrOutOfGas))
 }
 
 func TestDeploySuccess(t *testing.T) {
@@ -352,12 +378,501 @@ func TestCallSuccess(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, true, receipt.Success)
 
-	returns := receipt.ReturnData
-	assert.Equal(t, len(returns), 1+AddressLength)
-
-	// type is `Address`
-	assert.Equal(t, returns[0], byte(0x40))
+	returns, err := receipt.Returns()
+	assert.Nil(t, err)
+	assert.Len(t, returns, 1)
 
 	// expected loaded Address to be `102030405060708090102030405060708090AABB`
-	assert.Equal(t, returns[1:], []byte{16, 32, 48, 64, 80, 96, 112, 128, 144, 16, 32, 48, 64, 80, 96, 112, 128, 144, 170, 187})
+	addr, err := returns[0].AsAddress()
+	assert.Nil(t, err)
+	assert.Equal(t, addr, Address{16, 32, 48, 64, 80, 96, 112, 128, 144, 16, 32, 48, 64, 80, 96, 112, 128, 144, 170, 187})
+}
+
+func TestValidateDeployRebuilt(t *testing.T) {
+	rt := runtimeSetup(t)
+	defer rt.Destroy()
+
+	template, err := DecodeDeployMessage(readFile(t, "inputs/template_example.svm"))
+	assert.Nil(t, err)
+
+	msg, err := EncodeDeployMessage(NewDeployMessage(template.Code(), template.Data(), template.Ctors()))
+	assert.Nil(t, err)
+
+	valid, err := rt.ValidateDeploy(msg)
+	assert.True(t, valid)
+	assert.Nil(t, err)
+}
+
+func TestLayerLifecycle(t *testing.T) {
+	rt := runtimeSetup(t)
+	defer rt.Destroy()
+
+	err := rt.Open(Layer(1))
+	assert.True(t, errors.Is(err, ErrLayerAlreadyOpen))
+
+	params := NewTestParams()
+	params.Layer = Layer(2)
+	_, err = executeTx(t, rt, "inputs/template_example.svm", params, func(rt *Runtime, env *Envelope, msg []byte, ctx *Context) (interface{}, error) {
+		return rt.Deploy(env, msg, ctx)
+	})
+	assert.True(t, errors.Is(err, ErrUnexpectedLayer))
+
+	layer, _, err := rt.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, Layer(1), layer)
+
+	_, _, err = rt.Commit()
+	assert.True(t, errors.Is(err, ErrLayerNotOpen))
+
+	_, err = executeTx(t, rt, "inputs/template_example.svm", params, func(rt *Runtime, env *Envelope, msg []byte, ctx *Context) (interface{}, error) {
+		return rt.Deploy(env, msg, ctx)
+	})
+	assert.True(t, errors.Is(err, ErrLayerNotOpen))
+
+	var layerErr *LayerError
+	err = rt.Open(Layer(3))
+	assert.True(t, errors.As(err, &layerErr))
+	assert.Equal(t, &LayerError{Layer: Layer(3), LastCommitted: Layer(1)}, layerErr)
+
+	_, err = rt.Rewind(Layer(2))
+	assert.True(t, errors.Is(err, ErrUnexpectedLayer))
+
+	_, err = rt.Rewind(Layer(0))
+	assert.Nil(t, err)
+	assert.Nil(t, rt.Open(Layer(1)))
+}
+
+func TestLayerSession(t *testing.T) {
+	api, err := Init()
+	assert.Nil(t, err)
+
+	rt, err := api.NewRuntime(true, "")
+	assert.Nil(t, err)
+	defer rt.Destroy()
+
+	session, err := rt.BeginLayer(Layer(1))
+	assert.Nil(t, err)
+
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000000000), GasFee(0))
+	tx := NewTransaction(DeployType, env, readFile(t, "inputs/template_example.svm"), TxId{})
+	receipt, err := session.Apply(tx)
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success())
+
+	result, err := session.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, Layer(1), result.Layer)
+	assert.Equal(t, []*TxReceipt{receipt}, result.Receipts)
+
+	state, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, state, result.State)
+
+	_, err = session.Apply(tx)
+	assert.True(t, errors.Is(err, ErrSessionEnded))
+}
+
+func TestRuntimeStateIndex(t *testing.T) {
+	rt := runtimeSetup(t)
+	defer rt.Destroy()
+
+	genesis, err := rt.StateAt(Layer(0))
+	assert.Nil(t, err)
+
+	_, state1, err := rt.Commit()
+	assert.Nil(t, err)
+
+	assert.Nil(t, rt.Open(Layer(2)))
+	_, state2, err := rt.Commit()
+	assert.Nil(t, err)
+
+	layer, state, err := rt.LastCommitted()
+	assert.Nil(t, err)
+	assert.Equal(t, Layer(2), layer)
+	assert.Equal(t, state2, state)
+
+	layers, err := rt.CommittedLayers(Layer(0), Layer(2))
+	assert.Nil(t, err)
+	assert.Equal(t, []LayerState{{Layer(0), genesis}, {Layer(1), state1}, {Layer(2), state2}}, layers)
+
+	_, err = rt.Rewind(Layer(1))
+	assert.Nil(t, err)
+
+	_, err = rt.StateAt(Layer(2))
+	assert.True(t, errors.Is(err, ErrLayerNotCommitted))
+
+	state, err = rt.StateAt(Layer(1))
+	assert.Nil(t, err)
+	assert.Equal(t, state1, state)
+}
+
+func TestRuntimeStateIndexPersisted(t *testing.T) {
+	api, err := Init()
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "svm")
+	rt, err := api.NewRuntime(false, path)
+	assert.Nil(t, err)
+	defer rt.Destroy()
+
+	assert.Nil(t, rt.Open(Layer(1)))
+	layer, state, err := rt.Commit()
+	assert.Nil(t, err)
+
+	committed, err := rt.StateAt(layer)
+	assert.Nil(t, err)
+	assert.Equal(t, state, committed)
+
+	// the index is persisted next to the `SVM` storage directory (and not inside it)
+	_, err = os.Stat(stateIndexPath(path))
+	assert.Nil(t, err)
+	_, err = os.Stat(filepath.Join(path, "state_roots"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNewRuntimeStateIndexFailure(t *testing.T) {
+	api, err := Init()
+	assert.Nil(t, err)
+
+	EnableLeakDetection()
+	defer DisableLeakDetection()
+
+	// the state index can't be loaded, since its path is taken by a directory
+	path := t.TempDir()
+	assert.Nil(t, os.Mkdir(stateIndexPath(path), 0755))
+
+	count := api.RuntimesCount()
+	rt, err := api.NewRuntime(false, path)
+	assert.Nil(t, rt)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, count, api.RuntimesCount())
+	assert.Empty(t, LeakedRuntimes())
+}
+
+func TestExecuteLayer(t *testing.T) {
+	api, err := Init()
+	assert.Nil(t, err)
+
+	rt, err := api.NewRuntime(true, "")
+	assert.Nil(t, err)
+	defer rt.Destroy()
+
+	msg := readFile(t, "inputs/template_example.svm")
+	txs := []*Transaction{
+		NewTransaction(DeployType, NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000000000), GasFee(0)), msg, TxId{0x01}),
+		NewTransaction(DeployType, NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(10), GasFee(0)), msg, TxId{0x02}),
+	}
+
+	execution, err := rt.ExecuteLayer(Layer(1), txs, RollbackOnFailure)
+	assert.Nil(t, err)
+	assert.False(t, execution.Committed)
+	assert.Equal(t, 1, execution.Failed)
+	assert.True(t, errors.Is(execution.Receipts[1].Err(), ErrOutOfGas))
+
+	execution, err = rt.ExecuteLayer(Layer(1), txs, CommitAlways)
+	assert.Nil(t, err)
+	assert.True(t, execution.Committed)
+	assert.Equal(t, Layer(1), execution.Layer)
+	assert.Equal(t, execution.Receipts[0].GasUsed()+execution.Receipts[1].GasUsed(), execution.GasUsed)
+
+	state, err := rt.StateAt(Layer(1))
+	assert.Nil(t, err)
+	assert.Equal(t, state, execution.State)
+}
+
+func TestExecuteCallOutOfGas(t *testing.T) {
+	rt := runtimeSetup(t)
+	defer rt.Destroy()
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, Address{}, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(10), GasFee(0))
+
+	receipt, err := rt.ExecuteCall(env, msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+	assert.False(t, receipt.Success())
+	assert.Nil(t, receipt.Call)
+	assert.True(t, errors.Is(receipt.Err(), ErrOutOfGas))
+}
+
+func TestEstimateGas(t *testing.T) {
+	rt := runtimeSetup(t)
+	defer rt.Destroy()
+
+	err := rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)})
+	assert.Nil(t, err)
+	spawnTarget(t, rt, Layer(1))
+
+	before, err := rt.StateHash()
+	assert.Nil(t, err)
+
+	msg := readFile(t, "inputs/call/store_addr.json.bin")
+	env := NewEnvelope(Address{}, Amount(10), TxNonce{}, Gas(0), GasFee(0))
+
+	estimate, err := rt.EstimateGas(CallType, env, msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+	assert.True(t, estimate.GasUsed > 0)
+	assert.True(t, estimate.GasLimit >= estimate.GasUsed)
+
+	after, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+
+	account, err := rt.GetAccount(Address{})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(100), account.Balance)
+
+	// the uncommitted changes preceding the estimation are kept
+	_, state, err := rt.Commit()
+	assert.Nil(t, err)
+	assert.NotEqual(t, before, state)
+}
+
+func TestSimulate(t *testing.T) {
+	rt := runtimeSetup(t)
+	defer rt.Destroy()
+
+	err := rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)})
+	assert.Nil(t, err)
+	spawnTarget(t, rt, Layer(1))
+	_, before, err := rt.Commit()
+	assert.Nil(t, err)
+
+	ctx := NewContext(Layer(2), TxId{})
+	env := NewEnvelope(Address{}, Amount(10), TxNonce{}, Gas(1000000000), GasFee(0))
+
+	deployed, err := rt.SimulateDeploy(env, readFile(t, "inputs/template_example.svm"), ctx)
+	assert.Nil(t, err)
+	assert.True(t, deployed.Success)
+
+	called, err := rt.SimulateCall(env, readFile(t, "inputs/call/store_addr.json.bin"), ctx)
+	assert.Nil(t, err)
+	assert.True(t, called.Success)
+
+	after, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+
+	account, err := rt.GetAccount(Address{})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(100), account.Balance)
+
+	assert.Nil(t, rt.Open(Layer(2)))
+	_, state, err := rt.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, StateLength, len(state))
+}
+
+func TestSimulateReplayFailure(t *testing.T) {
+	rt := runtimeSetup(t)
+	defer rt.Destroy()
+
+	err := rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)})
+	assert.Nil(t, err)
+
+	// a journal entry which can't be replayed
+	replayErr := errors.New("replay has failed")
+	rt.journal = append(rt.journal, func() error {
+		return replayErr
+	})
+
+	ctx := NewContext(Layer(1), TxId{})
+	env := NewEnvelope(Address{}, Amount(10), TxNonce{}, Gas(1000000000), GasFee(0))
+	msg, err := EncodeCallMessage(NewCallMessage(0, Address{0x01}, "store_addr", nil, nil))
+	assert.Nil(t, err)
+
+	_, err = rt.SimulateCall(env, msg, ctx)
+	assert.True(t, errors.Is(err, ErrLayerBroken))
+	assert.True(t, errors.Is(err, replayErr))
+
+	// the partially replayed layer is refused until rewinded
+	_, _, err = rt.Commit()
+	assert.True(t, errors.Is(err, ErrLayerBroken))
+	_, err = rt.Call(env, msg, ctx)
+	assert.True(t, errors.Is(err, ErrLayerBroken))
+	_, err = rt.SimulateCall(env, msg, ctx)
+	assert.True(t, errors.Is(err, ErrLayerBroken))
+	assert.True(t, errors.Is(rt.Open(Layer(1)), ErrLayerBroken))
+
+	_, err = rt.Rewind(Layer(0))
+	assert.Nil(t, err)
+	assert.Nil(t, rt.Open(Layer(1)))
+	_, _, err = rt.Commit()
+	assert.Nil(t, err)
+}
+
+func TestRuntimeClosed(t *testing.T) {
+	rt := runtimeSetup(t)
+	assert.Nil(t, rt.Close())
+	assert.Nil(t, rt.Close())
+	rt.Destroy()
+
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000), GasFee(0))
+	msg := []byte{0, 0, 0, 1}
+	ctx := NewContext(Layer(1), TxId{})
+
+	errs := []error{}
+	collect := func(_ interface{}, err error) {
+		errs = append(errs, err)
+	}
+
+	collect(rt.ValidateDeploy(msg))
+	collect(rt.ValidateSpawn(msg))
+	collect(rt.ValidateCall(msg))
+	collect(rt.Deploy(env, msg, ctx))
+	collect(rt.Spawn(env, msg, ctx))
+	collect(rt.Call(env, msg, ctx))
+	collect(rt.Verify(env, msg, ctx))
+	collect(rt.ExecuteCall(env, msg, ctx))
+	collect(rt.EstimateGas(CallType, env, msg, ctx))
+	collect(rt.SimulateDeploy(env, msg, ctx))
+	collect(rt.SimulateSpawn(env, msg, ctx))
+	collect(rt.SimulateCall(env, msg, ctx))
+	collect(nil, rt.Open(Layer(2)))
+	collect(rt.BeginLayer(Layer(2)))
+	collect(rt.ExecuteLayer(Layer(2), nil, CommitAlways))
+	collect(rt.Rewind(Layer(0)))
+	collect(rt.StateHash())
+	collect(rt.StateAt(Layer(0)))
+	collect(rt.CommittedLayers(Layer(0), Layer(1)))
+	collect(rt.GetAccount(Address{}))
+	collect(nil, rt.CreateAccount(Account{}))
+	collect(nil, rt.IncreaseBalance(Address{}, Amount(1)))
+
+	_, _, err := rt.Commit()
+	collect(nil, err)
+	_, _, err = rt.LastCommitted()
+	collect(nil, err)
+
+	for i, err := range errs {
+		assert.True(t, errors.Is(err, ErrRuntimeClosed), "call #%d returned %v", i, err)
+	}
+}
+
+func TestRuntimeMetrics(t *testing.T) {
+	api, err := Init()
+	assert.Nil(t, err)
+
+	m := NewInMemoryMetrics()
+	api.SetMetrics(m)
+	defer api.SetMetrics(nil)
+
+	rt, err := api.NewRuntime(true, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, m.LiveRuntimes())
+
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(1000)}))
+	spawnTarget(t, rt, Layer(1))
+
+	msg := readFile(t, "inputs/call/store_addr.json.bin")
+	ctx := NewContext(Layer(1), TxId{})
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000000000), GasFee(0))
+
+	valid, err := rt.ValidateCall(msg)
+	assert.True(t, valid)
+	assert.Nil(t, err)
+	_, err = rt.ValidateCall([]byte{0, 0, 0, 0})
+	assert.NotNil(t, err)
+
+	succeeded, err := rt.Call(env, msg, ctx)
+	assert.Nil(t, err)
+	assert.True(t, succeeded.Success)
+
+	failed, err := rt.Call(env, readFile(t, "inputs/call/nonexistent_func.json.bin"), ctx)
+	assert.Nil(t, err)
+	assert.False(t, failed.Success)
+
+	_, err = rt.Verify(env, msg, ctx)
+	assert.Nil(t, err)
+
+	_, _, err = rt.Commit()
+	assert.Nil(t, err)
+	_, err = rt.Rewind(Layer(0))
+	assert.Nil(t, err)
+
+	validations := m.Op(OpValidateCall)
+	assert.Equal(t, 2, validations.Count)
+	assert.Equal(t, 1, validations.Errors)
+
+	calls := m.Op(OpCall)
+	assert.Equal(t, 2, calls.Count)
+	assert.Equal(t, 0, calls.Errors)
+	assert.Equal(t, succeeded.GasUsed+failed.GasUsed, calls.GasUsed)
+	assert.Equal(t, map[RuntimeErrorKind]int{FuncNotFound: 1}, calls.Failures)
+	assert.Equal(t, 1, m.Op(OpVerify).Count)
+	assert.Equal(t, 1, m.Op(OpDeploy).Count)
+	assert.Equal(t, 1, m.Op(OpSpawn).Count)
+
+	commits, last := m.Commits()
+	assert.Equal(t, 1, commits)
+	assert.Equal(t, Layer(1), last)
+	assert.Equal(t, 1, m.Rewinds())
+
+	assert.Nil(t, rt.Close())
+	assert.Equal(t, 0, m.LiveRuntimes())
+}
+
+func TestRuntimeLogger(t *testing.T) {
+	api, err := Init()
+	assert.Nil(t, err)
+
+	logger := &recordingLogger{}
+	api.SetLogger(logger)
+	defer api.SetLogger(nil)
+
+	rt, err := api.NewRuntime(true, "")
+	assert.Nil(t, err)
+	defer rt.Close()
+
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{0x10}, Balance: Amount(1000)}))
+	spawnTarget(t, rt, Layer(1))
+
+	msg := readFile(t, "inputs/call/nonexistent_func.json.bin")
+	env := NewEnvelope(Address{0x10}, Amount(0), TxNonce{}, Gas(1000000000), GasFee(0))
+
+	receipt, err := rt.Call(env, msg, NewContext(Layer(1), TxId{0x20}))
+	assert.Nil(t, err)
+	assert.False(t, receipt.Success)
+
+	_, _, err = rt.Commit()
+	assert.Nil(t, err)
+
+	events := logger.find("layer opened")
+	assert.Len(t, events, 1)
+	assert.Equal(t, LogDebug, events[0].level)
+
+	events = logger.find("transaction failed")
+	assert.Len(t, events, 1)
+	assert.Equal(t, LogInfo, events[0].level)
+	assert.Equal(t, Layer(1), events[0].fields[LogKeyLayer])
+	assert.Equal(t, TxId{0x20}, events[0].fields[LogKeyTxId])
+	assert.Equal(t, Address{0x10}, events[0].fields[LogKeyPrincipal])
+	assert.Equal(t, FuncNotFound, events[0].fields[LogKeyErrorKind])
+
+	events = logger.find("layer committed")
+	assert.Len(t, events, 1)
+	assert.Equal(t, Layer(1), events[0].fields[LogKeyLayer])
+}
+
+func TestRuntimeSilentByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	rt := runtimeSetup(t)
+	defer rt.Close()
+	spawnTarget(t, rt, Layer(1))
+
+	msg := readFile(t, "inputs/call/nonexistent_func.json.bin")
+	receipt, err := rt.Call(NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000000000), GasFee(0)), msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+	assert.False(t, receipt.Success)
+
+	_, _, err = rt.Commit()
+	assert.Nil(t, err)
+
+	assert.Empty(t, buf.String())
 }
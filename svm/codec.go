@@ -1,6 +1,9 @@
 package svm
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+	"errors"
+)
 
 // In other words, holds fields which are part of any transaction regardless of its type (i.e `Deploy/Spawn/Call`).
 /// Encoding of a binary [`Envelope`].
@@ -115,3 +118,32 @@ func decodeTxNonce(bytes []byte) (TxNonce, []byte) {
 
 	return TxNonce{Upper: upper, Lower: lower}, bytes[16:]
 }
+
+func encodeVersion(bytes []byte, version uint16) []byte {
+	var buf [VersionLength]byte
+	binary.BigEndian.PutUint16(buf[:], version)
+
+	return append(bytes, buf[:]...)
+}
+
+func encodeAddress(bytes []byte, addr [AddressLength]byte) []byte {
+	return append(bytes, addr[:]...)
+}
+
+func encodeString(bytes []byte, s string) ([]byte, error) {
+	if len(s) > MaxStringLength {
+		return nil, errors.New("`string` is too long")
+	}
+
+	bytes = append(bytes, byte(len(s)))
+	return append(bytes, s...), nil
+}
+
+func encodeBlob(bytes []byte, blob []byte) ([]byte, error) {
+	if len(blob) > MaxBlobLength {
+		return nil, errors.New("`blob` is too long")
+	}
+
+	bytes = append(bytes, byte(len(blob)))
+	return append(bytes, blob...), nil
+}
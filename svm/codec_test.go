@@ -1,11 +1,18 @@
 package svm
 
 import (
+	"io/ioutil"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func readFile(t *testing.T, path string) []byte {
+	bytes, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	return bytes
+}
+
 func decodeEnvelope(bytes []byte) (*Envelope, []byte) {
 	principal, bytes := decodeAddress(bytes)
 	amount, bytes := decodeAmount(bytes)
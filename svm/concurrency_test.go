@@ -0,0 +1,167 @@
+// +build cgo,!nosvm
+
+package svm
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeConcurrentUsage(t *testing.T) {
+	rt := runtimeSetup(t)
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(1000000)}))
+	spawnTarget(t, rt, Layer(1))
+	_, _, err := rt.Commit()
+	assert.Nil(t, err)
+
+	template := readFile(t, "inputs/template_example.svm")
+	call := readFile(t, "inputs/call/store_addr.json.bin")
+
+	const layers = 20
+	var wg sync.WaitGroup
+
+	// a single goroutine drives the layers
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for layer := Layer(2); layer < layers; layer++ {
+			assert.Nil(t, rt.Open(layer))
+
+			env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000000), GasFee(0))
+			_, err := rt.Deploy(env, template, NewContext(layer, TxId{}))
+			assert.Nil(t, err)
+
+			_, _, err = rt.Commit()
+			assert.Nil(t, err)
+		}
+	}()
+
+	// others execute transactions whenever a layer happens to be open
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				env := NewEnvelope(Address{}, Amount(1), TxNonce{}, Gas(1000000), GasFee(0))
+				layer, _, err := rt.LastCommitted()
+				assert.Nil(t, err)
+
+				receipt, err := rt.Call(env, call, NewContext(layer+1, TxId{}))
+				if err != nil {
+					assert.True(t, errors.Is(err, ErrLayerNotOpen) || errors.Is(err, ErrUnexpectedLayer))
+				} else {
+					assert.True(t, receipt.Success)
+				}
+			}
+		}()
+	}
+
+	// and the rest run queries (serialized with the transactions whenever they call into `SVM`)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				_, err := rt.GetAccount(Address{})
+				assert.Nil(t, err)
+
+				_, err = rt.StateHash()
+				assert.Nil(t, err)
+
+				_, err = rt.StateAt(Layer(1))
+				assert.Nil(t, err)
+
+				valid, _ := rt.ValidateCall(call)
+				assert.True(t, valid)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	layer, _, err := rt.LastCommitted()
+	assert.Nil(t, err)
+	assert.Equal(t, Layer(layers-1), layer)
+
+	rt.Destroy()
+}
+
+func TestRuntimeContextReporting(t *testing.T) {
+	rt := runtimeSetup(t)
+	defer rt.Destroy()
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(1000000)}))
+	spawnTarget(t, rt, Layer(1))
+
+	call := readFile(t, "inputs/call/store_addr.json.bin")
+
+	var wg sync.WaitGroup
+
+	// the reporting of the `*Context` variants doesn't race with replacing the `Metrics` and the `Logger`
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			assert.Nil(t, rt.SetMetrics(NewInMemoryMetrics()))
+			assert.Nil(t, rt.SetLogger(NewTextLogger(ioutil.Discard, LogDebug)))
+		}
+	}()
+
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				env := NewEnvelope(Address{}, Amount(1), TxNonce{}, Gas(1000000), GasFee(0))
+				receipt, err := rt.CallContext(context.Background(), env, call, NewContext(Layer(1), TxId{}))
+				assert.Nil(t, err)
+				assert.True(t, receipt.Success)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestRuntimeUseAfterDestroy(t *testing.T) {
+	rt := runtimeSetup(t)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				_, err := rt.GetAccount(Address{})
+				if errors.Is(err, ErrRuntimeClosed) {
+					return
+				}
+			}
+		}()
+	}
+
+	rt.Destroy()
+	wg.Wait()
+
+	_, err := rt.GetAccount(Address{})
+	assert.True(t, errors.Is(err, ErrRuntimeClosed))
+	_, err = rt.StateHash()
+	assert.True(t, errors.Is(err, ErrRuntimeClosed))
+	_, err = rt.ValidateCall([]byte{0, 0, 0, 1})
+	assert.True(t, errors.Is(err, ErrRuntimeClosed))
+	_, _, err = rt.Commit()
+	assert.True(t, errors.Is(err, ErrRuntimeClosed))
+
+	// destroying twice is a no-op
+	rt.Destroy()
+}
@@ -0,0 +1,131 @@
+// +build cgo,!nosvm
+
+package svm
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// The states of a transaction executed under a `context.Context`.
+const (
+	execRunning int32 = iota
+	execFinished
+	execAbandoned
+)
+
+// Same as `Deploy`, but gives up waiting for the transaction once `goCtx` is done.
+//
+// See `CallContext` for the handling of cancellations.
+func (rt *Runtime) DeployContext(goCtx context.Context, env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error) {
+	object, err := rt.executeContext(goCtx, OpDeploy, env, msg, ctx, rt.deployAction)
+	if err != nil {
+		return nil, err
+	}
+	return object.(*DeployReceipt), nil
+}
+
+// Same as `Spawn`, but gives up waiting for the transaction once `goCtx` is done.
+//
+// See `CallContext` for the handling of cancellations.
+func (rt *Runtime) SpawnContext(goCtx context.Context, env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
+	object, err := rt.executeContext(goCtx, OpSpawn, env, msg, ctx, rt.spawnAction)
+	if err != nil {
+		return nil, err
+	}
+	return object.(*SpawnReceipt), nil
+}
+
+// Same as `Call`, but gives up waiting for the transaction once `goCtx` is done.
+//
+// # Notes
+//
+// * When `goCtx` is already done, the transaction isn't executed at all.
+//
+// * Otherwise, once `goCtx` is done (e.g its deadline has passed) `goCtx.Err()` is returned right away
+//   (i.e `context.DeadlineExceeded` or `context.Canceled`).
+//   Since `SVM` can't be interrupted, the transaction keeps running in the background and its changes are discarded
+//   as soon as it completes. Until then, any other usage of the `Runtime` blocks.
+//
+// * An abandoned transaction leaves the open layer exactly as it was (it can be further executed, committed or rewinded).
+//   If its changes can't be discarded, the open layer is left broken instead: `Commit` (and any further execution)
+//   returns a `*BrokenLayerError` until the `Runtime` is rewinded.
+func (rt *Runtime) CallContext(goCtx context.Context, env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	object, err := rt.executeContext(goCtx, OpCall, env, msg, ctx, rt.callAction)
+	if err != nil {
+		return nil, err
+	}
+	return object.(*CallReceipt), nil
+}
+
+type execResult struct {
+	object interface{}
+	err    error
+}
+
+// Executes the transaction (via `rt.execute`) in the background, and waits for it as long as `goCtx` isn't done.
+//
+// Whoever of the caller and the background execution gets first to move `state` out of `execRunning` wins.
+// When the caller wins, the background execution discards the transaction changes once it completes.
+// Since nobody waits for the outcome anymore, a failure to discard them is kept by marking the layer as broken (see `restore`).
+//
+// The outcome is reported by the background execution while still holding the `Runtime`
+// (an abandoned transaction is reported along with `goCtx.Err()`, or the failure to discard it).
+// The inputs are copied, since an abandoned execution outlives its caller.
+func (rt *Runtime) executeContext(goCtx context.Context, op Op, env *Envelope, msg []byte, ctx *Context, action svmAction) (interface{}, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	envCopy, msgCopy, ctxCopy := *env, append([]byte{}, msg...), *ctx
+
+	err := goCtx.Err()
+	if err == nil {
+		err = rt.layers.ensureExecutable(ctx)
+	}
+	if err != nil {
+		rt.report(op, start, env, ctx, nil, err)
+		rt.mu.Unlock()
+		return nil, err
+	}
+
+	var state int32
+	done := make(chan execResult, 1)
+
+	go func() {
+		defer rt.mu.Unlock()
+
+		n := len(rt.journal)
+		object, err := rt.execute(&envCopy, msgCopy, &ctxCopy, action)
+		if rt.execHook != nil {
+			rt.execHook()
+		}
+
+		if !atomic.CompareAndSwapInt32(&state, execRunning, execFinished) {
+			object, err = nil, goCtx.Err()
+			if len(rt.journal) > n {
+				if restoreErr := rt.restore(n); restoreErr != nil {
+					err = restoreErr
+				}
+			}
+			rt.report(op, start, &envCopy, &ctxCopy, object, err)
+			return
+		}
+
+		rt.report(op, start, &envCopy, &ctxCopy, object, err)
+		done <- execResult{object, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.object, res.err
+	case <-goCtx.Done():
+		if atomic.CompareAndSwapInt32(&state, execRunning, execAbandoned) {
+			return nil, goCtx.Err()
+		}
+		res := <-done
+		return res.object, res.err
+	}
+}
@@ -0,0 +1,188 @@
+// +build cgo,!nosvm
+
+package svm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func deadlineSetup(t *testing.T) (*Runtime, *Envelope, []byte, *Context) {
+	rt := runtimeSetup(t)
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(1000)}))
+	spawnTarget(t, rt, Layer(1))
+
+	msg := readFile(t, "inputs/call/store_addr.json.bin")
+	env := NewEnvelope(Address{}, Amount(10), TxNonce{}, Gas(1000000000), GasFee(0))
+	return rt, env, msg, NewContext(Layer(1), TxId{})
+}
+
+func TestRuntimeCallContext(t *testing.T) {
+	rt, env, msg, ctx := deadlineSetup(t)
+	defer rt.Close()
+
+	goCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	receipt, err := rt.CallContext(goCtx, env, msg, ctx)
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success)
+
+	account, err := rt.GetAccount(Address{})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(990), account.Balance)
+}
+
+func TestRuntimeCallContextCanceledBeforeExecution(t *testing.T) {
+	rt, env, msg, ctx := deadlineSetup(t)
+	defer rt.Close()
+
+	before, err := rt.StateHash()
+	assert.Nil(t, err)
+
+	goCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	receipt, err := rt.CallContext(goCtx, env, msg, ctx)
+	assert.Nil(t, receipt)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	goCtx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	_, err = rt.DeployContext(goCtx, env, readFile(t, "inputs/template_example.svm"), ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	_, err = rt.SpawnContext(goCtx, env, readFile(t, "inputs/spawn/initialize.json.bin"), ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	after, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+}
+
+func TestRuntimeCallContextDeadlineDuringExecution(t *testing.T) {
+	rt, env, msg, ctx := deadlineSetup(t)
+	defer rt.Close()
+
+	before, err := rt.StateHash()
+	assert.Nil(t, err)
+
+	goCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// emulates a pathological transaction, still running when the caller has given up on it
+	returned := make(chan struct{})
+	rt.execHook = func() {
+		<-returned
+	}
+
+	start := time.Now()
+	receipt, err := rt.CallContext(goCtx, env, msg, ctx)
+	close(returned)
+	assert.Nil(t, receipt)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, time.Since(start) < time.Second)
+
+	// blocks until the abandoned transaction is discarded
+	after, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+	rt.execHook = nil
+
+	account, err := rt.GetAccount(Address{})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(1000), account.Balance)
+
+	// the layer is left clean, thus it can be further executed and committed
+	called, err := rt.Call(env, msg, ctx)
+	assert.Nil(t, err)
+	assert.True(t, called.Success)
+
+	layer, _, err := rt.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, Layer(1), layer)
+
+	account, err = rt.GetAccount(Address{})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(990), account.Balance)
+}
+
+func TestRuntimeCallContextCanceledOnCompletion(t *testing.T) {
+	rt, env, msg, ctx := deadlineSetup(t)
+	defer rt.Close()
+
+	// the cancellation and the completion of the transaction race each other,
+	// either way the outcome returned matches the state left behind
+	balance := Amount(1000)
+	for i := 0; i < 50; i++ {
+		goCtx, cancel := context.WithCancel(context.Background())
+		rt.execHook = cancel
+
+		receipt, err := rt.CallContext(goCtx, env, msg, ctx)
+		if err == nil {
+			assert.True(t, receipt.Success)
+			balance -= env.Amount
+		} else {
+			assert.Nil(t, receipt)
+			assert.True(t, errors.Is(err, context.Canceled))
+		}
+
+		// blocks until an abandoned transaction is discarded
+		account, err := rt.GetAccount(Address{})
+		assert.Nil(t, err)
+		assert.Equal(t, balance, account.Balance)
+	}
+	rt.execHook = nil
+
+	_, _, err := rt.Commit()
+	assert.Nil(t, err)
+}
+
+func TestRuntimeCallContextRestoreFailure(t *testing.T) {
+	rt, env, msg, ctx := deadlineSetup(t)
+	defer rt.Close()
+
+	// a journal entry which can't be replayed
+	replayErr := errors.New("replay has failed")
+	rt.journal = append(rt.journal, func() error {
+		return replayErr
+	})
+
+	metrics := NewInMemoryMetrics()
+	assert.Nil(t, rt.SetMetrics(metrics))
+
+	goCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	returned := make(chan struct{})
+	rt.execHook = func() {
+		<-returned
+	}
+
+	receipt, err := rt.CallContext(goCtx, env, msg, ctx)
+	close(returned)
+	assert.Nil(t, receipt)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// the abandoned transaction couldn't be discarded, thus the layer is refused until rewinded
+	_, _, err = rt.Commit()
+	assert.True(t, errors.Is(err, ErrLayerBroken))
+	assert.True(t, errors.Is(err, replayErr))
+	rt.execHook = nil
+
+	// the abandoned transaction is reported along with the failure to discard it
+	assert.Equal(t, 1, metrics.Op(OpCall).Count)
+	assert.Equal(t, 1, metrics.Op(OpCall).Errors)
+
+	_, err = rt.Call(env, msg, ctx)
+	assert.True(t, errors.Is(err, ErrLayerBroken))
+
+	_, err = rt.Rewind(Layer(0))
+	assert.Nil(t, err)
+	assert.Nil(t, rt.Open(Layer(1)))
+	_, _, err = rt.Commit()
+	assert.Nil(t, err)
+}
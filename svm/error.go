@@ -1,14 +1,14 @@
 package svm
 
-type ValidateErrorKind byte
-type RuntimeErrorKind int
-
-const (
-	ParseError    ValidateErrorKind = 0
-	ProgramError  ValidateErrorKind = 1
-	FixedGasError ValidateErrorKind = 2
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
 )
 
+type RuntimeErrorKind int
+
 const (
 	OOG                  RuntimeErrorKind = 0
 	TemplateNotFound     RuntimeErrorKind = 1
@@ -22,11 +22,74 @@ const (
 	FuncInvalidSignature RuntimeErrorKind = 9
 )
 
+// Returned by `ValidateDeploy`, `ValidateSpawn` and `ValidateCall` when a `Message` is invalid.
+//
+// `Message` holds the error payload returned by `SVM` as-is.
+// The kind of the validation failure isn't decoded, since the `SVM` C-API doesn't document the layout of that payload.
 type ValidateError struct {
-	Kind    ValidateErrorKind
 	Message string
 }
 
+func (e *ValidateError) Error() string {
+	if e.Message == "" {
+		return "invalid message"
+	}
+	return "invalid message: " + e.Message
+}
+
+// Wraps the error payload returned by `SVM` when a validation fails.
+func decodeValidateError(bytes []byte) error {
+	return &ValidateError{Message: string(bytes)}
+}
+
+// Returned by every `Runtime` operation when `go-svm` is built without the SVM library
+// (i.e without `cgo` or with the `nosvm` build tag).
+var ErrSvmNotAvailable = errors.New("SVM is not available (built without the SVM library)")
+
+// Returned when using a `Runtime` after it has been destroyed.
+var ErrRuntimeClosed = errors.New("runtime has been closed")
+
+// Sentinel errors matching each `RuntimeErrorKind`.
+//
+// A `*RuntimeError` unwraps to the sentinel of its `Kind`, so that
+// `errors.Is(receipt.Err(), ErrOutOfGas)` can be used instead of comparing kinds by hand.
+var (
+	ErrOutOfGas             = errors.New("out of gas")
+	ErrTemplateNotFound     = errors.New("template not found")
+	ErrAccountNotFound      = errors.New("account not found")
+	ErrCompilationFailed    = errors.New("compilation failed")
+	ErrInstantiationFailed  = errors.New("instantiation failed")
+	ErrFuncNotFound         = errors.New("function not found")
+	ErrFuncFailed           = errors.New("function failed")
+	ErrFuncNotCtor          = errors.New("function is not a constructor")
+	ErrFuncNotAllowed       = errors.New("function not allowed")
+	ErrFuncInvalidSignature = errors.New("function has an invalid signature")
+)
+
+var runtimeErrorSentinels = map[RuntimeErrorKind]error{
+	OOG:                  ErrOutOfGas,
+	TemplateNotFound:     ErrTemplateNotFound,
+	AccountNotFound:      ErrAccountNotFound,
+	CompilationFailed:    ErrCompilationFailed,
+	InstantiationFailed:  ErrInstantiationFailed,
+	FuncNotFound:         ErrFuncNotFound,
+	FuncFailed:           ErrFuncFailed,
+	FuncNotCtor:          ErrFuncNotCtor,
+	FuncNotAllowed:       ErrFuncNotAllowed,
+	FuncInvalidSignature: ErrFuncInvalidSignature,
+}
+
+func (kind RuntimeErrorKind) String() string {
+	if sentinel, ok := runtimeErrorSentinels[kind]; ok {
+		return sentinel.Error()
+	}
+	return "RuntimeErrorKind(" + strconv.Itoa(int(kind)) + ")"
+}
+
+// Holds the reason a transaction has failed.
+//
+// Only the fields relevant to the `Kind` are set
+// (for example, an `OOG` error has none of `Template`, `Target`, `Function` and `Message`).
 type RuntimeError struct {
 	Kind     RuntimeErrorKind
 	Target   Address
@@ -34,3 +97,106 @@ type RuntimeError struct {
 	Template TemplateAddr
 	Message  string
 }
+
+func (e *RuntimeError) Error() string {
+	details := []string{}
+
+	hasTemplate, hasTarget, hasFunction, hasMessage, _ := runtimeErrorFields(e.Kind)
+	if hasTemplate {
+		details = append(details, "template "+hex.EncodeToString(e.Template[:]))
+	}
+	if hasTarget {
+		details = append(details, "target "+hex.EncodeToString(e.Target[:]))
+	}
+	if hasFunction {
+		details = append(details, "function `"+e.Function+"`")
+	}
+
+	msg := e.Kind.String()
+	if len(details) > 0 {
+		msg += " (" + strings.Join(details, ", ") + ")"
+	}
+	if hasMessage && e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Returns the sentinel error matching `e.Kind` (e.g `ErrOutOfGas` for `OOG`).
+func (e *RuntimeError) Unwrap() error {
+	return runtimeErrorSentinels[e.Kind]
+}
+
+// Returned when binary data (e.g a `Receipt` given by `SVM` or a `Call Message`) can't be decoded.
+//
+// For a `Receipt`, it usually implies a mismatch between the `go-svm` and `SVM` versions in use.
+type DecodeError struct {
+	// What was being decoded (e.g `Receipt` or `Call Message`).
+	Subject string
+	Message string
+}
+
+func newDecodeError(msg string) *DecodeError {
+	return &DecodeError{Subject: "Receipt", Message: msg}
+}
+
+// Sets the `Subject` of `err` when it's a `*DecodeError` (the shared decoding helpers assume a `Receipt`).
+func withDecodeSubject(err error, subject string) error {
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		decodeErr.Subject = subject
+	}
+	return err
+}
+
+func (e *DecodeError) Error() string {
+	return "Received a corrupted " + e.Subject + ": " + e.Message
+}
+
+// Errors returned when the `Open` / `Commit` / `Rewind` layer lifecycle isn't respected.
+//
+// Transactions may only be executed between an `Open` and the matching `Commit` (or `Rewind`).
+var (
+	ErrLayerAlreadyOpen = errors.New("a layer is already open")
+	ErrLayerNotOpen     = errors.New("no layer is open")
+	ErrUnexpectedLayer  = errors.New("unexpected layer")
+	ErrLayerBroken      = errors.New("the uncommitted state is broken")
+)
+
+// Returned when a `Layer` given to `Open`, `Rewind` or within a `Context` doesn't match the `Runtime` layer.
+//
+// A `*LayerError` unwraps to `ErrUnexpectedLayer`.
+type LayerError struct {
+	Layer         Layer
+	LastCommitted Layer
+}
+
+func (e *LayerError) Error() string {
+	return "unexpected layer " + strconv.FormatUint(uint64(e.Layer), 10) +
+		" (last committed layer is " + strconv.FormatUint(uint64(e.LastCommitted), 10) + ")"
+}
+
+func (e *LayerError) Unwrap() error {
+	return ErrUnexpectedLayer
+}
+
+// Returned once the uncommitted state couldn't be restored after a simulation or an abandoned transaction
+// (i.e `SVM` may hold partially replayed changes).
+//
+// Until the `Runtime` is rewinded, `Open`, `Commit`, the transactions and the simulations are all refused.
+// A `*BrokenLayerError` matches `ErrLayerBroken` (via `errors.Is`) and unwraps to the restore failure.
+type BrokenLayerError struct {
+	Err error
+}
+
+func (e *BrokenLayerError) Error() string {
+	return ErrLayerBroken.Error() + " (restoring it has failed: " + e.Err.Error() + "), a `Rewind` is required"
+}
+
+func (e *BrokenLayerError) Is(target error) bool {
+	return target == ErrLayerBroken
+}
+
+func (e *BrokenLayerError) Unwrap() error {
+	return e.Err
+}
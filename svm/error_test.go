@@ -0,0 +1,69 @@
+package svm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeErrorIs(t *testing.T) {
+	other := errors.New("other")
+
+	for kind, sentinel := range runtimeErrorSentinels {
+		receipt := &CallReceipt{Success: false, Error: &RuntimeError{Kind: kind}}
+
+		assert.True(t, errors.Is(receipt.Err(), sentinel))
+		assert.False(t, errors.Is(receipt.Err(), other))
+
+		var rtError *RuntimeError
+		assert.True(t, errors.As(receipt.Err(), &rtError))
+		assert.Equal(t, kind, rtError.Kind)
+	}
+
+	rtError := &RuntimeError{Kind: FuncFailed}
+	assert.False(t, errors.Is(rtError, ErrOutOfGas))
+}
+
+func TestReceiptErrNil(t *testing.T) {
+	assert.Nil(t, (&DeployReceipt{Success: true}).Err())
+	assert.Nil(t, (&SpawnReceipt{Success: true}).Err())
+	assert.Nil(t, (&CallReceipt{Success: true}).Err())
+
+	receipt := &DeployReceipt{Success: false, Error: &RuntimeError{Kind: OOG}}
+	assert.True(t, errors.Is(receipt.Err(), ErrOutOfGas))
+}
+
+func TestRuntimeErrorMessage(t *testing.T) {
+	rtError := &RuntimeError{
+		Kind:     FuncFailed,
+		Template: TemplateAddr{0xAA},
+		Target:   Address{0xBB},
+		Function: "store_addr",
+		Message:  "panicked",
+	}
+	assert.Equal(t,
+		"function failed (template aa00000000000000000000000000000000000000, target bb00000000000000000000000000000000000000, function `store_addr`): panicked",
+		rtError.Error())
+
+	assert.Equal(t, "out of gas", (&RuntimeError{Kind: OOG}).Error())
+}
+
+func TestErrorKindString(t *testing.T) {
+	assert.Equal(t, "out of gas", OOG.String())
+	assert.Equal(t, "function is not a constructor", FuncNotCtor.String())
+	assert.Equal(t, "RuntimeErrorKind(100)", RuntimeErrorKind(100).String())
+}
+
+func TestDecodeValidateError(t *testing.T) {
+	// the `SVM` text is kept as-is
+	for _, payload := range []string{"\x00invalid", "Invalid Wasm: unexpected opcode"} {
+		err := decodeValidateError([]byte(payload))
+
+		var validateErr *ValidateError
+		assert.True(t, errors.As(err, &validateErr))
+		assert.Equal(t, payload, validateErr.Message)
+	}
+	assert.Equal(t, "invalid message: Invalid Wasm", decodeValidateError([]byte("Invalid Wasm")).Error())
+	assert.Equal(t, "invalid message", decodeValidateError(nil).Error())
+}
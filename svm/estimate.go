@@ -0,0 +1,66 @@
+package svm
+
+import (
+	"errors"
+	"strconv"
+)
+
+// The `GasLimit` under which `EstimateGas` executes a transaction.
+const EstimateGasLimit Gas = 1 << 48
+
+// The margin (in percents) added on top of the estimated `GasUsed` when recommending a `GasLimit`.
+const EstimateGasMargin Gas = 10
+
+// Holds the outcome of `EstimateGas`.
+type GasEstimate struct {
+	// The `Gas` used by the transaction when executed against the current (uncommitted) state.
+	GasUsed Gas
+
+	// The recommended `GasLimit` for the transaction `Envelope` (i.e `GasUsed` plus `EstimateGasMargin` percents).
+	GasLimit Gas
+}
+
+func newGasEstimate(receipt *TxReceipt) (*GasEstimate, error) {
+	gasUsed := receipt.GasUsed()
+	estimate := &GasEstimate{
+		GasUsed:  gasUsed,
+		GasLimit: gasUsed + gasUsed*EstimateGasMargin/100,
+	}
+	return estimate, receipt.Err()
+}
+
+func ensureEstimable(txType TxType) error {
+	if txType != SpawnType && txType != CallType {
+		return errors.New("gas can be estimated only for `Spawn` and `Call` transactions (got `TxType` " + strconv.Itoa(int(txType)) + ")")
+	}
+	return nil
+}
+
+// Same as `Runtime.EstimateGas`.
+func (rt *FakeRuntime) EstimateGas(txType TxType, env *Envelope, msg []byte, ctx *Context) (*GasEstimate, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	if err := ensureEstimable(txType); err != nil {
+		return nil, err
+	}
+
+	estimateEnv := *env
+	estimateEnv.GasLimit = EstimateGasLimit
+
+	defer rt.restore(rt.state.clone())
+
+	receipt := &TxReceipt{Type: txType, TxId: ctx.TxId}
+	var err error
+	if txType == SpawnType {
+		receipt.Spawn, err = rt.spawn(&estimateEnv, msg, ctx)
+	} else {
+		receipt.Call, err = rt.call(&estimateEnv, msg, ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newGasEstimate(receipt)
+}
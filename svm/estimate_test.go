@@ -0,0 +1,46 @@
+package svm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeEstimateGas(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.GasUsed = Gas(1000)
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)}))
+	template := fakeDeploy(t, rt)
+
+	msg, err := EncodeSpawnMessage(NewSpawnMessage(0, template, "My Account", "initialize", []byte{}))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(10), TxNonce{}, Gas(0), GasFee(0))
+
+	estimate, err := rt.EstimateGas(SpawnType, env, msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+	assert.Equal(t, &GasEstimate{GasUsed: Gas(1000), GasLimit: Gas(1100)}, estimate)
+
+	account, err := rt.GetAccount(Address{})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(100), account.Balance)
+	_, err = rt.GetAccount(fakeSpawnAddress(env, msg))
+	assert.NotNil(t, err)
+
+	_, err = rt.EstimateGas(DeployType, env, msg, NewContext(Layer(1), TxId{}))
+	assert.NotNil(t, err)
+}
+
+func TestFakeEstimateGasFailure(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.GasUsed = Gas(1000)
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, Address{0xFF}, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(0), GasFee(0))
+
+	estimate, err := rt.EstimateGas(CallType, env, msg, NewContext(Layer(1), TxId{}))
+	assert.NotNil(t, estimate)
+	assert.True(t, errors.Is(err, ErrAccountNotFound))
+}
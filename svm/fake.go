@@ -0,0 +1,758 @@
+package svm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ VM = (*FakeRuntime)(nil)
+
+// `FakeRuntime` is a deterministic, pure-Go and in-memory implementation of `VM`.
+// It doesn't require the SVM C-API and is intended for unit-testing code depending on `VM`.
+//
+// No Wasm code is executed. Instead:
+//
+// * `Deploy` stores the `Template` under an `Address` derived from the `Deploy Message`.
+// * `Spawn` creates an `Account` (funded with the `Envelope` `Amount`) under an `Address` derived from the transaction.
+// * `Call` transfers the `Envelope` `Amount` from the `Principal` to the `target` Account.
+// * `Verify` succeeds as long as the `target` Account exists.
+//
+// The layer lifecycle is enforced exactly as by `Runtime` (see `Open`, `Commit` and `Rewind`).
+//
+// Each default receipt can be replaced by setting the matching `*Hook` field.
+// A `Hook` takes over completely (i.e no state changes are applied by the `FakeRuntime` itself).
+// Since it's called while the `FakeRuntime` is held, a `Hook` must not call back into the `FakeRuntime`.
+//
+// Same as `Runtime`, a `FakeRuntime` is safe for concurrent usage (all of its operations are serialized),
+// and once destroyed any further usage returns `ErrRuntimeClosed`.
+type FakeRuntime struct {
+	mu sync.Mutex
+
+	DeployHook func(env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error)
+	SpawnHook  func(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error)
+	CallHook   func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error)
+	VerifyHook func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error)
+
+	// The `GasUsed` reported by the default receipts.
+	// A transaction whose `GasLimit` is lower than it fails with an `OOG` error.
+	GasUsed Gas
+
+	// The current (uncommitted) state.
+	state *fakeState
+
+	// `history[i]` holds the state committed under layer `i` (layer `0` is the empty genesis state).
+	history []*fakeState
+
+	layers layerTracker
+
+	metrics   Metrics
+	logger    Logger
+	destroyed bool
+}
+
+type fakeState struct {
+	accounts  map[Address]Account
+	templates map[TemplateAddr][]string // the `Ctors` of each `Template`
+	spawnedBy map[Address]TemplateAddr
+}
+
+func NewFakeRuntime() *FakeRuntime {
+	genesis := newFakeState()
+
+	return &FakeRuntime{
+		state:   genesis.clone(),
+		history: []*fakeState{genesis},
+		metrics: noopMetrics{},
+		logger:  noopLogger{},
+	}
+}
+
+func newFakeState() *fakeState {
+	return &fakeState{
+		accounts:  make(map[Address]Account),
+		templates: make(map[TemplateAddr][]string),
+		spawnedBy: make(map[Address]TemplateAddr),
+	}
+}
+
+func (s *fakeState) clone() *fakeState {
+	other := newFakeState()
+	for addr, account := range s.accounts {
+		other.accounts[addr] = account
+	}
+	for addr, ctors := range s.templates {
+		other.templates[addr] = ctors
+	}
+	for addr, template := range s.spawnedBy {
+		other.spawnedBy[addr] = template
+	}
+	return other
+}
+
+// Computes a deterministic hash over the whole state.
+func (s *fakeState) hash() State {
+	h := sha256.New()
+
+	accounts := make([]Address, 0, len(s.accounts))
+	for addr := range s.accounts {
+		accounts = append(accounts, addr)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return string(accounts[i][:]) < string(accounts[j][:])
+	})
+	for _, addr := range accounts {
+		account := s.accounts[addr]
+		template := s.spawnedBy[addr]
+
+		var buf [AmountLength + TxNonceLength]byte
+		binary.BigEndian.PutUint64(buf[0:], uint64(account.Balance))
+		binary.BigEndian.PutUint64(buf[8:], account.Counter.Upper)
+		binary.BigEndian.PutUint64(buf[16:], account.Counter.Lower)
+
+		h.Write(addr[:])
+		h.Write(buf[:])
+		h.Write(template[:])
+	}
+
+	templates := make([]TemplateAddr, 0, len(s.templates))
+	for addr := range s.templates {
+		templates = append(templates, addr)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return string(templates[i][:]) < string(templates[j][:])
+	})
+	for _, addr := range templates {
+		h.Write(addr[:])
+		for _, ctor := range s.templates[addr] {
+			h.Write([]byte{byte(len(ctor))})
+			h.Write([]byte(ctor))
+		}
+	}
+
+	var state State
+	copy(state[:], h.Sum(nil))
+	return state
+}
+
+func (s *fakeState) transfer(from Address, to Address, amount Amount) error {
+	if amount == 0 {
+		return nil
+	}
+
+	sender, ok := s.accounts[from]
+	if !ok || sender.Balance < amount {
+		return errors.New("`Principal` has insufficient balance")
+	}
+
+	sender.Balance -= amount
+	s.accounts[from] = sender
+
+	receiver := s.accounts[to]
+	receiver.Addr = to
+	receiver.Balance += amount
+	s.accounts[to] = receiver
+	return nil
+}
+
+func (rt *FakeRuntime) ValidateDeploy(msg []byte) (bool, error) {
+	if err := rt.lock(); err != nil {
+		return false, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	valid, err := validateFakeDeploy(msg)
+	rt.report(OpValidateDeploy, start, nil, nil, nil, err)
+	return valid, err
+}
+
+func validateFakeDeploy(msg []byte) (bool, error) {
+	template, err := DecodeDeployMessage(msg)
+	if err != nil || template.Code() == nil {
+		return false, &ValidateError{Message: "malformed `Deploy Message`"}
+	}
+	return true, nil
+}
+
+func (rt *FakeRuntime) ValidateSpawn(msg []byte) (bool, error) {
+	if err := rt.lock(); err != nil {
+		return false, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	valid, err := validateFakeSpawn(msg)
+	rt.report(OpValidateSpawn, start, nil, nil, nil, err)
+	return valid, err
+}
+
+func validateFakeSpawn(msg []byte) (bool, error) {
+	if _, err := decodeSpawnMessage(msg); err != nil {
+		return false, &ValidateError{Message: "malformed `Spawn Message`"}
+	}
+	return true, nil
+}
+
+func (rt *FakeRuntime) ValidateCall(msg []byte) (bool, error) {
+	if err := rt.lock(); err != nil {
+		return false, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	valid, err := validateFakeCall(msg)
+	rt.report(OpValidateCall, start, nil, nil, nil, err)
+	return valid, err
+}
+
+func validateFakeCall(msg []byte) (bool, error) {
+	if _, err := decodeCallMessage(msg); err != nil {
+		return false, &ValidateError{Message: "malformed `Call Message`"}
+	}
+	return true, nil
+}
+
+func (rt *FakeRuntime) Deploy(env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	err := rt.layers.ensureExecutable(ctx)
+
+	var receipt *DeployReceipt
+	if err == nil {
+		receipt, err = rt.deploy(env, msg, ctx)
+	}
+	rt.report(OpDeploy, start, env, ctx, receipt, err)
+	return receipt, err
+}
+
+func (rt *FakeRuntime) deploy(env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error) {
+	if rt.DeployHook != nil {
+		return rt.DeployHook(env, msg, ctx)
+	}
+
+	template, err := DecodeDeployMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	if env.GasLimit < rt.GasUsed {
+		return &DeployReceipt{Success: false, Error: &RuntimeError{Kind: OOG}, Logs: []Log{}}, nil
+	}
+
+	var addr TemplateAddr
+	hash := sha256.Sum256(msg)
+	copy(addr[:], hash[:])
+
+	ctors := []string{}
+	if section := template.Ctors(); section != nil {
+		ctors = section.Ctors
+	}
+	rt.state.templates[addr] = ctors
+
+	receipt := &DeployReceipt{
+		Success:      true,
+		TemplateAddr: addr,
+		GasUsed:      rt.GasUsed,
+		Logs:         []Log{},
+	}
+	return receipt, nil
+}
+
+func (rt *FakeRuntime) Spawn(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	err := rt.layers.ensureExecutable(ctx)
+
+	var receipt *SpawnReceipt
+	if err == nil {
+		receipt, err = rt.spawn(env, msg, ctx)
+	}
+	rt.report(OpSpawn, start, env, ctx, receipt, err)
+	return receipt, err
+}
+
+func (rt *FakeRuntime) spawn(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
+	if rt.SpawnHook != nil {
+		return rt.SpawnHook(env, msg, ctx)
+	}
+
+	spawn, err := decodeSpawnMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	if env.GasLimit < rt.GasUsed {
+		return &SpawnReceipt{Success: false, Error: &RuntimeError{Kind: OOG}, Logs: []Log{}}, nil
+	}
+
+	ctors, ok := rt.state.templates[spawn.Template]
+	if !ok {
+		rtError := &RuntimeError{Kind: TemplateNotFound, Template: spawn.Template}
+		return &SpawnReceipt{Success: false, Error: rtError, Logs: []Log{}}, nil
+	}
+	if !containsString(ctors, spawn.CtorName) {
+		rtError := &RuntimeError{Kind: FuncNotCtor, Template: spawn.Template, Function: spawn.CtorName}
+		return &SpawnReceipt{Success: false, Error: rtError, Logs: []Log{}}, nil
+	}
+
+	addr := fakeSpawnAddress(env, msg)
+	if err := rt.state.transfer(env.Principal, addr, env.Amount); err != nil {
+		return nil, err
+	}
+	if _, ok := rt.state.accounts[addr]; !ok {
+		rt.state.accounts[addr] = Account{Addr: addr}
+	}
+	rt.state.spawnedBy[addr] = spawn.Template
+
+	receipt := &SpawnReceipt{
+		Success:         true,
+		AccountAddr:     addr,
+		InitState:       rt.state.hash(),
+		ReturnData:      []byte{},
+		GasUsed:         rt.GasUsed,
+		Logs:            []Log{},
+		TouchedAccounts: []Address{env.Principal, addr},
+	}
+	return receipt, nil
+}
+
+func (rt *FakeRuntime) Call(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	return rt.callLocked(env, msg, ctx)
+}
+
+func (rt *FakeRuntime) callLocked(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	start := time.Now()
+	err := rt.layers.ensureExecutable(ctx)
+
+	var receipt *CallReceipt
+	if err == nil {
+		receipt, err = rt.call(env, msg, ctx)
+	}
+	rt.report(OpCall, start, env, ctx, receipt, err)
+	return receipt, err
+}
+
+func (rt *FakeRuntime) call(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	if rt.CallHook != nil {
+		return rt.CallHook(env, msg, ctx)
+	}
+	return rt.execCall(env, msg, true)
+}
+
+func (rt *FakeRuntime) Verify(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	return rt.verifyLocked(env, msg, ctx)
+}
+
+func (rt *FakeRuntime) verifyLocked(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	start := time.Now()
+	receipt, err := rt.verify(env, msg, ctx)
+	rt.report(OpVerify, start, env, ctx, receipt, err)
+	return receipt, err
+}
+
+func (rt *FakeRuntime) verify(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	if rt.VerifyHook != nil {
+		return rt.VerifyHook(env, msg, ctx)
+	}
+	return rt.execCall(env, msg, false)
+}
+
+func (rt *FakeRuntime) execCall(env *Envelope, msg []byte, transfer bool) (*CallReceipt, error) {
+	call, err := decodeCallMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	if env.GasLimit < rt.GasUsed {
+		return &CallReceipt{Success: false, Error: &RuntimeError{Kind: OOG}, Logs: []Log{}}, nil
+	}
+
+	if _, ok := rt.state.accounts[call.Target]; !ok {
+		rtError := &RuntimeError{Kind: AccountNotFound, Target: call.Target}
+		return &CallReceipt{Success: false, Error: rtError, Logs: []Log{}}, nil
+	}
+
+	template := rt.state.spawnedBy[call.Target]
+	if containsString(rt.state.templates[template], call.FuncName) {
+		rtError := &RuntimeError{Kind: FuncNotAllowed, Template: template, Target: call.Target, Function: call.FuncName}
+		return &CallReceipt{Success: false, Error: rtError, Logs: []Log{}}, nil
+	}
+
+	if transfer {
+		if err := rt.state.transfer(env.Principal, call.Target, env.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	receipt := &CallReceipt{
+		Success:         true,
+		NewState:        rt.state.hash(),
+		ReturnData:      []byte{},
+		GasUsed:         rt.GasUsed,
+		Logs:            []Log{},
+		TouchedAccounts: []Address{call.Target},
+	}
+	return receipt, nil
+}
+
+// Same as `Runtime.SimulateDeploy`.
+func (rt *FakeRuntime) SimulateDeploy(env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	defer rt.restore(rt.state.clone())
+	return rt.deploy(env, msg, ctx)
+}
+
+// Same as `Runtime.SimulateSpawn`.
+func (rt *FakeRuntime) SimulateSpawn(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	defer rt.restore(rt.state.clone())
+	return rt.spawn(env, msg, ctx)
+}
+
+// Same as `Runtime.SimulateCall`.
+func (rt *FakeRuntime) SimulateCall(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	defer rt.restore(rt.state.clone())
+	return rt.call(env, msg, ctx)
+}
+
+// Same as `Runtime.DeployContext`.
+//
+// Since the `FakeRuntime` executes synchronously, `goCtx` is checked before and after the execution.
+// When `goCtx` is done by the time the transaction completes, its changes are discarded.
+func (rt *FakeRuntime) DeployContext(goCtx context.Context, env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	err := rt.ensureContext(goCtx, ctx)
+
+	var receipt *DeployReceipt
+	if err == nil {
+		snapshot := rt.state.clone()
+
+		receipt, err = rt.deploy(env, msg, ctx)
+		if goCtx.Err() != nil {
+			rt.restore(snapshot)
+			receipt, err = nil, goCtx.Err()
+		}
+	}
+	rt.report(OpDeploy, start, env, ctx, receipt, err)
+	return receipt, err
+}
+
+// Same as `Runtime.SpawnContext` (see `DeployContext`).
+func (rt *FakeRuntime) SpawnContext(goCtx context.Context, env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	err := rt.ensureContext(goCtx, ctx)
+
+	var receipt *SpawnReceipt
+	if err == nil {
+		snapshot := rt.state.clone()
+
+		receipt, err = rt.spawn(env, msg, ctx)
+		if goCtx.Err() != nil {
+			rt.restore(snapshot)
+			receipt, err = nil, goCtx.Err()
+		}
+	}
+	rt.report(OpSpawn, start, env, ctx, receipt, err)
+	return receipt, err
+}
+
+// Same as `Runtime.CallContext` (see `DeployContext`).
+func (rt *FakeRuntime) CallContext(goCtx context.Context, env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	start := time.Now()
+	err := rt.ensureContext(goCtx, ctx)
+
+	var receipt *CallReceipt
+	if err == nil {
+		snapshot := rt.state.clone()
+
+		receipt, err = rt.call(env, msg, ctx)
+		if goCtx.Err() != nil {
+			rt.restore(snapshot)
+			receipt, err = nil, goCtx.Err()
+		}
+	}
+	rt.report(OpCall, start, env, ctx, receipt, err)
+	return receipt, err
+}
+
+func (rt *FakeRuntime) ensureContext(goCtx context.Context, ctx *Context) error {
+	if err := goCtx.Err(); err != nil {
+		return err
+	}
+	return rt.layers.ensureExecutable(ctx)
+}
+
+func (rt *FakeRuntime) restore(snapshot *fakeState) {
+	rt.state = snapshot
+}
+
+// Expects `layer` to equal the last committed (or rewinded) layer plus one.
+func (rt *FakeRuntime) Open(layer Layer) error {
+	if err := rt.lock(); err != nil {
+		return err
+	}
+	defer rt.mu.Unlock()
+
+	if err := rt.layers.open(layer); err != nil {
+		return err
+	}
+	rt.logger.Log(LogDebug, "layer opened", LogField{LogKeyLayer, layer})
+	return nil
+}
+
+func (rt *FakeRuntime) Commit() (Layer, State, error) {
+	if err := rt.lock(); err != nil {
+		return Layer(0), State{}, err
+	}
+	defer rt.mu.Unlock()
+
+	if err := rt.layers.ensureCommittable(); err != nil {
+		return Layer(0), State{}, err
+	}
+
+	committed := rt.state.clone()
+	rt.history = append(rt.history, committed)
+
+	layer, state := rt.layers.committed(), committed.hash()
+	rt.metrics.ObserveCommit(layer)
+	rt.logger.Log(LogInfo, "layer committed", LogField{LogKeyLayer, layer}, LogField{LogKeyState, state})
+	return layer, state, nil
+}
+
+func (rt *FakeRuntime) Rewind(layer Layer) (State, error) {
+	if err := rt.lock(); err != nil {
+		return State{}, err
+	}
+	defer rt.mu.Unlock()
+
+	if err := rt.layers.ensureRewindable(layer); err != nil {
+		return State{}, err
+	}
+
+	rt.history = rt.history[:layer+1]
+	rt.state = rt.history[layer].clone()
+	rt.layers.rewinded(layer)
+
+	state := rt.history[layer].hash()
+	rt.metrics.ObserveRewind(layer)
+	rt.logger.Log(LogInfo, "layer rewinded", LogField{LogKeyLayer, layer}, LogField{LogKeyState, state})
+	return state, nil
+}
+
+// Returns the `State` of the last committed layer.
+func (rt *FakeRuntime) StateHash() (State, error) {
+	if err := rt.lock(); err != nil {
+		return State{}, err
+	}
+	defer rt.mu.Unlock()
+
+	return rt.history[rt.layers.last].hash(), nil
+}
+
+// Returns the `State` committed under `layer` (see `Runtime.StateAt`).
+func (rt *FakeRuntime) StateAt(layer Layer) (State, error) {
+	if err := rt.lock(); err != nil {
+		return State{}, err
+	}
+	defer rt.mu.Unlock()
+
+	if layer > rt.layers.last {
+		return State{}, ErrLayerNotCommitted
+	}
+	return rt.history[layer].hash(), nil
+}
+
+func (rt *FakeRuntime) LastCommitted() (Layer, State, error) {
+	if err := rt.lock(); err != nil {
+		return Layer(0), State{}, err
+	}
+	defer rt.mu.Unlock()
+
+	return rt.layers.last, rt.history[rt.layers.last].hash(), nil
+}
+
+func (rt *FakeRuntime) CommittedLayers(from Layer, to Layer) ([]LayerState, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	if from > to {
+		return nil, errors.New("`from` cannot exceed `to`")
+	}
+
+	layers := []LayerState{}
+	for layer := from; layer <= to && layer <= rt.layers.last; layer++ {
+		layers = append(layers, LayerState{Layer: layer, State: rt.history[layer].hash()})
+	}
+	return layers, nil
+}
+
+func (rt *FakeRuntime) GetAccount(addr Address) (Account, error) {
+	if err := rt.lock(); err != nil {
+		return Account{}, err
+	}
+	defer rt.mu.Unlock()
+
+	account, ok := rt.state.accounts[addr]
+	if !ok {
+		return Account{}, errors.New("`Account` not found")
+	}
+	return account, nil
+}
+
+func (rt *FakeRuntime) CreateAccount(account Account) error {
+	if err := rt.lock(); err != nil {
+		return err
+	}
+	defer rt.mu.Unlock()
+
+	rt.state.accounts[account.Addr] = account
+	return nil
+}
+
+func (rt *FakeRuntime) IncreaseBalance(addr Address, amount Amount) error {
+	if err := rt.lock(); err != nil {
+		return err
+	}
+	defer rt.mu.Unlock()
+
+	return rt.increaseBalance(addr, amount)
+}
+
+func (rt *FakeRuntime) increaseBalance(addr Address, amount Amount) error {
+	account, ok := rt.state.accounts[addr]
+	if !ok {
+		return errors.New("`Account` not found")
+	}
+
+	account.Balance += amount
+	rt.state.accounts[addr] = account
+	return nil
+}
+
+// Same as `Runtime.SetMetrics`.
+func (rt *FakeRuntime) SetMetrics(metrics Metrics) error {
+	if err := rt.lock(); err != nil {
+		return err
+	}
+	defer rt.mu.Unlock()
+
+	rt.metrics.RuntimeClosed()
+	rt.metrics = metricsOrNoop(metrics)
+	rt.metrics.RuntimeCreated()
+	return nil
+}
+
+// Same as `Runtime.SetLogger`.
+func (rt *FakeRuntime) SetLogger(logger Logger) error {
+	if err := rt.lock(); err != nil {
+		return err
+	}
+	defer rt.mu.Unlock()
+
+	rt.logger = loggerOrNoop(logger)
+	return nil
+}
+
+// Reports a completed `op` to the `Metrics` and the `Logger` of the `FakeRuntime`.
+func (rt *FakeRuntime) report(op Op, start time.Time, env *Envelope, ctx *Context, receipt interface{}, err error) {
+	observeOp(rt.metrics, op, start, receipt, err)
+	logOp(rt.logger, op, env, ctx, receipt, err)
+}
+
+// Same as `Runtime.lock`.
+func (rt *FakeRuntime) lock() error {
+	rt.mu.Lock()
+	if rt.destroyed {
+		rt.mu.Unlock()
+		return ErrRuntimeClosed
+	}
+	return nil
+}
+
+// Releases the `FakeRuntime` (same as `Close`).
+func (rt *FakeRuntime) Destroy() {
+	rt.Close()
+}
+
+// Same as `Runtime.Close`.
+func (rt *FakeRuntime) Close() error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if !rt.destroyed {
+		rt.destroyed = true
+		rt.metrics.RuntimeClosed()
+	}
+	return nil
+}
+
+func fakeSpawnAddress(env *Envelope, msg []byte) Address {
+	envBytes := encodeEnvelope(env)
+
+	h := sha256.New()
+	h.Write(envBytes[:])
+	h.Write(msg)
+
+	var addr Address
+	copy(addr[:], h.Sum(nil))
+	return addr
+}
+
+func containsString(items []string, item string) bool {
+	for _, s := range items {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
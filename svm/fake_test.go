@@ -0,0 +1,352 @@
+package svm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeDeploy(t *testing.T, rt *FakeRuntime) TemplateAddr {
+	msg := readFile(t, "inputs/template_example.svm")
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000), GasFee(0))
+
+	receipt, err := rt.Deploy(env, msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success)
+	return receipt.TemplateAddr
+}
+
+func fakeSpawn(t *testing.T, rt *FakeRuntime, template TemplateAddr, ctor string, amount Amount) *SpawnReceipt {
+	msg, err := EncodeSpawnMessage(NewSpawnMessage(0, template, "My Account", ctor, []byte{}))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, amount, TxNonce{}, Gas(1000), GasFee(0))
+
+	receipt, err := rt.Spawn(env, msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+	return receipt
+}
+
+func fakeCall(t *testing.T, rt *FakeRuntime, target Address, funcName string, amount Amount) *CallReceipt {
+	msg, err := EncodeCallMessage(NewCallMessage(0, target, funcName, []byte{}, []byte{}))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, amount, TxNonce{}, Gas(1000), GasFee(0))
+
+	receipt, err := rt.Call(env, msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+	return receipt
+}
+
+func TestFakeValidate(t *testing.T) {
+	rt := NewFakeRuntime()
+
+	valid, err := rt.ValidateDeploy(readFile(t, "inputs/template_example.svm"))
+	assert.True(t, valid)
+	assert.Nil(t, err)
+
+	var validateErr *ValidateError
+	for _, validate := range []func([]byte) (bool, error){rt.ValidateDeploy, rt.ValidateSpawn, rt.ValidateCall} {
+		valid, err = validate([]byte{0, 0, 0, 0})
+		assert.False(t, valid)
+		assert.True(t, errors.As(err, &validateErr))
+	}
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, Address{}, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	valid, err = rt.ValidateCall(msg)
+	assert.True(t, valid)
+	assert.Nil(t, err)
+}
+
+func TestFakeSpawnAndCall(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)}))
+
+	template := fakeDeploy(t, rt)
+
+	spawned := fakeSpawn(t, rt, template, "initialize", Amount(30))
+	assert.True(t, spawned.Success)
+	assert.Contains(t, spawned.TouchedAccounts, spawned.AccountAddr)
+	assert.Contains(t, spawned.TouchedAccounts, Address{})
+
+	receipt := fakeCall(t, rt, spawned.AccountAddr, "store_addr", Amount(20))
+	assert.True(t, receipt.Success)
+	assert.Equal(t, []Address{spawned.AccountAddr}, receipt.TouchedAccounts)
+
+	principal, err := rt.GetAccount(Address{})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(50), principal.Balance)
+
+	account, err := rt.GetAccount(spawned.AccountAddr)
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(50), account.Balance)
+}
+
+func TestFakeFailures(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+	template := fakeDeploy(t, rt)
+
+	spawned := fakeSpawn(t, rt, TemplateAddr{0xFF}, "initialize", Amount(0))
+	assert.True(t, errors.Is(spawned.Err(), ErrTemplateNotFound))
+
+	spawned = fakeSpawn(t, rt, template, "load_addr", Amount(0))
+	assert.True(t, errors.Is(spawned.Err(), ErrFuncNotCtor))
+
+	receipt := fakeCall(t, rt, Address{0xFF}, "store_addr", Amount(0))
+	assert.True(t, errors.Is(receipt.Err(), ErrAccountNotFound))
+
+	spawned = fakeSpawn(t, rt, template, "initialize", Amount(0))
+	assert.True(t, spawned.Success)
+	receipt = fakeCall(t, rt, spawned.AccountAddr, "initialize", Amount(0))
+	assert.True(t, errors.Is(receipt.Err(), ErrFuncNotAllowed))
+
+	rt.GasUsed = Gas(2000)
+	receipt = fakeCall(t, rt, spawned.AccountAddr, "store_addr", Amount(0))
+	assert.True(t, errors.Is(receipt.Err(), ErrOutOfGas))
+}
+
+func TestFakeHooks(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+	expected := &CallReceipt{Success: true, ReturnData: []byte{0x10}}
+	rt.CallHook = func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+		return expected, nil
+	}
+
+	receipt := fakeCall(t, rt, Address{0xFF}, "store_addr", Amount(0))
+	assert.Equal(t, expected, receipt)
+}
+
+func TestFakeLayers(t *testing.T) {
+	rt := NewFakeRuntime()
+	genesis, err := rt.StateHash()
+	assert.Nil(t, err)
+
+	assert.True(t, errors.Is(rt.Open(Layer(2)), ErrUnexpectedLayer))
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.True(t, errors.Is(rt.Open(Layer(1)), ErrLayerAlreadyOpen))
+
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{0x01}, Balance: Amount(10)}))
+	layer, state1, err := rt.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, Layer(1), layer)
+	assert.NotEqual(t, genesis, state1)
+
+	hash, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, state1, hash)
+
+	assert.Nil(t, rt.Open(Layer(2)))
+	assert.Nil(t, rt.IncreaseBalance(Address{0x01}, Amount(5)))
+	layer, state2, err := rt.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, Layer(2), layer)
+	assert.NotEqual(t, state1, state2)
+
+	state, err := rt.Rewind(Layer(1))
+	assert.Nil(t, err)
+	assert.Equal(t, state1, state)
+
+	account, err := rt.GetAccount(Address{0x01})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(10), account.Balance)
+
+	_, err = rt.StateAt(Layer(2))
+	assert.True(t, errors.Is(err, ErrLayerNotCommitted))
+	layers, err := rt.CommittedLayers(Layer(0), Layer(5))
+	assert.Nil(t, err)
+	assert.Equal(t, []LayerState{{Layer(0), genesis}, {Layer(1), state1}}, layers)
+
+	_, err = rt.Rewind(Layer(5))
+	assert.True(t, errors.Is(err, ErrUnexpectedLayer))
+
+	assert.Nil(t, rt.Open(Layer(2)))
+}
+
+func TestFakeDeterministic(t *testing.T) {
+	states := []State{}
+
+	for i := 0; i < 2; i++ {
+		rt := NewFakeRuntime()
+		assert.Nil(t, rt.Open(Layer(1)))
+		assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)}))
+		template := fakeDeploy(t, rt)
+		spawned := fakeSpawn(t, rt, template, "initialize", Amount(10))
+		assert.True(t, spawned.Success)
+
+		_, state, err := rt.Commit()
+		assert.Nil(t, err)
+		states = append(states, state)
+	}
+
+	assert.Equal(t, states[0], states[1])
+}
+
+func TestFakeLayerLifecycle(t *testing.T) {
+	rt := NewFakeRuntime()
+	msg, err := EncodeCallMessage(NewCallMessage(0, Address{}, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000), GasFee(0))
+
+	_, err = rt.Call(env, msg, NewContext(Layer(1), TxId{}))
+	assert.True(t, errors.Is(err, ErrLayerNotOpen))
+
+	_, _, err = rt.Commit()
+	assert.True(t, errors.Is(err, ErrLayerNotOpen))
+
+	assert.Nil(t, rt.Open(Layer(1)))
+	_, err = rt.Call(env, msg, NewContext(Layer(2), TxId{}))
+	var layerErr *LayerError
+	assert.True(t, errors.As(err, &layerErr))
+	assert.Equal(t, &LayerError{Layer: Layer(2), LastCommitted: Layer(0)}, layerErr)
+
+	_, err = rt.Call(env, msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+}
+
+func TestFakeCallContext(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)}))
+	spawned := fakeSpawn(t, rt, fakeDeploy(t, rt), "initialize", Amount(0))
+
+	before, err := rt.StateHash()
+	assert.Nil(t, err)
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, spawned.AccountAddr, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(10), TxNonce{}, Gas(1000), GasFee(0))
+	ctx := NewContext(Layer(1), TxId{})
+
+	// canceled before execution
+	goCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = rt.CallContext(goCtx, env, msg, ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+	_, err = rt.DeployContext(goCtx, env, readFile(t, "inputs/template_example.svm"), ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// the deadline passes during execution
+	goCtx, cancel = context.WithCancel(context.Background())
+	rt.SpawnHook = func(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
+		assert.Nil(t, rt.increaseBalance(Address{}, Amount(1)))
+		cancel()
+		return &SpawnReceipt{Success: true}, nil
+	}
+	_, err = rt.SpawnContext(goCtx, env, msg, ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	after, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+
+	receipt, err := rt.CallContext(context.Background(), env, msg, ctx)
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success)
+
+	principal, err := rt.GetAccount(Address{})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(90), principal.Balance)
+}
+
+func TestFakeCallContextDeadlineDuringExecution(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)}))
+	spawned := fakeSpawn(t, rt, fakeDeploy(t, rt), "initialize", Amount(0))
+
+	before, err := rt.StateHash()
+	assert.Nil(t, err)
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, spawned.AccountAddr, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(10), TxNonce{}, Gas(1000), GasFee(0))
+	ctx := NewContext(Layer(1), TxId{})
+
+	goCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// emulates a pathological transaction, still running when the deadline passes
+	rt.CallHook = func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+		assert.Nil(t, rt.increaseBalance(spawned.AccountAddr, Amount(1)))
+		<-goCtx.Done()
+		return &CallReceipt{Success: true}, nil
+	}
+
+	receipt, err := rt.CallContext(goCtx, env, msg, ctx)
+	assert.Nil(t, receipt)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	after, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+
+	// the layer is left clean, thus it can be further executed and committed
+	rt.CallHook = nil
+	receipt, err = rt.Call(env, msg, ctx)
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success)
+
+	layer, _, err := rt.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, Layer(1), layer)
+}
+
+func TestFakeConcurrentUsage(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(1000)}))
+	spawned := fakeSpawn(t, rt, fakeDeploy(t, rt), "initialize", Amount(0))
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, spawned.AccountAddr, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(1), TxNonce{}, Gas(1000), GasFee(0))
+	ctx := NewContext(Layer(1), TxId{})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 10; i++ {
+				receipt, err := rt.Call(env, msg, ctx)
+				assert.Nil(t, err)
+				assert.True(t, receipt.Success)
+
+				_, err = rt.StateHash()
+				assert.Nil(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	principal, err := rt.GetAccount(Address{})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(1000-80), principal.Balance)
+}
+
+func TestFakeUseAfterDestroy(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+	rt.Destroy()
+
+	_, err := rt.GetAccount(Address{})
+	assert.True(t, errors.Is(err, ErrRuntimeClosed))
+	_, err = rt.StateHash()
+	assert.True(t, errors.Is(err, ErrRuntimeClosed))
+	_, err = rt.ValidateCall([]byte{0, 0, 0, 1})
+	assert.True(t, errors.Is(err, ErrRuntimeClosed))
+	_, _, err = rt.Commit()
+	assert.True(t, errors.Is(err, ErrRuntimeClosed))
+	assert.True(t, errors.Is(rt.Open(Layer(2)), ErrRuntimeClosed))
+
+	// destroying twice is a no-op
+	rt.Destroy()
+	assert.Nil(t, rt.Close())
+}
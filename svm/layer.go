@@ -0,0 +1,92 @@
+package svm
+
+// Tracks the layer lifecycle of a runtime: `Open(last + 1)`, then any number of
+// `Deploy` / `Spawn` / `Call` transactions, and finally a `Commit` (or a `Rewind`).
+//
+// The tracker itself holds no `SVM` state, it only decides whether an operation is allowed.
+type layerTracker struct {
+	// The last committed (or rewinded) layer.
+	last Layer
+
+	opened bool
+
+	// Set when the uncommitted state couldn't be restored (see `Runtime.restore`).
+	// Until the next rewind, the layer can be neither executed nor committed.
+	broken error
+}
+
+// The layer currently open (meaningful only when `opened` is set).
+func (lt *layerTracker) current() Layer {
+	return lt.last + 1
+}
+
+func (lt *layerTracker) open(layer Layer) error {
+	if err := lt.ensureIntact(); err != nil {
+		return err
+	}
+	if lt.opened {
+		return ErrLayerAlreadyOpen
+	}
+	if layer != lt.current() {
+		return &LayerError{Layer: layer, LastCommitted: lt.last}
+	}
+
+	lt.opened = true
+	return nil
+}
+
+// Makes sure a transaction executed under `ctx` belongs to the open layer.
+func (lt *layerTracker) ensureExecutable(ctx *Context) error {
+	if err := lt.ensureIntact(); err != nil {
+		return err
+	}
+	if !lt.opened {
+		return ErrLayerNotOpen
+	}
+	if ctx.Layer != lt.current() {
+		return &LayerError{Layer: ctx.Layer, LastCommitted: lt.last}
+	}
+	return nil
+}
+
+func (lt *layerTracker) ensureCommittable() error {
+	if err := lt.ensureIntact(); err != nil {
+		return err
+	}
+	if !lt.opened {
+		return ErrLayerNotOpen
+	}
+	return nil
+}
+
+func (lt *layerTracker) ensureRewindable(layer Layer) error {
+	if layer > lt.last {
+		return &LayerError{Layer: layer, LastCommitted: lt.last}
+	}
+	return nil
+}
+
+// Makes sure the uncommitted state hasn't been left broken (only a rewind recovers it).
+func (lt *layerTracker) ensureIntact() error {
+	if lt.broken != nil {
+		return &BrokenLayerError{Err: lt.broken}
+	}
+	return nil
+}
+
+// Marks the uncommitted state as broken by `err`.
+func (lt *layerTracker) broke(err error) {
+	lt.broken = err
+}
+
+func (lt *layerTracker) committed() Layer {
+	lt.last = lt.current()
+	lt.opened = false
+	return lt.last
+}
+
+func (lt *layerTracker) rewinded(layer Layer) {
+	lt.last = layer
+	lt.opened = false
+	lt.broken = nil
+}
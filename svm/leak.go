@@ -0,0 +1,91 @@
+package svm
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Holds a `Runtime` which has been created (while leak detection was enabled) but hasn't been closed yet.
+type RuntimeLeak struct {
+	// The stack trace of the `NewRuntime` call which has created the `Runtime`.
+	Stack string
+}
+
+func (leak RuntimeLeak) String() string {
+	return "unclosed `Runtime` created at:\n" + leak.Stack
+}
+
+var leakDetector = struct {
+	sync.Mutex
+	enabled bool
+	created map[*Runtime]string
+}{
+	created: make(map[*Runtime]string),
+}
+
+// Starts recording the creation site of each new `Runtime`, until it's closed.
+//
+// Leak detection is opt-in (it's intended for tests), since capturing stack traces is costly.
+// Use `LeakedRuntimes` to report the runtimes which haven't been closed.
+func EnableLeakDetection() {
+	leakDetector.Lock()
+	defer leakDetector.Unlock()
+
+	leakDetector.enabled = true
+}
+
+// Stops recording new runtimes and forgets the ones recorded so far.
+func DisableLeakDetection() {
+	leakDetector.Lock()
+	defer leakDetector.Unlock()
+
+	leakDetector.enabled = false
+	leakDetector.created = make(map[*Runtime]string)
+}
+
+// Returns the runtimes created (while leak detection was enabled) which haven't been closed yet.
+func LeakedRuntimes() []RuntimeLeak {
+	leakDetector.Lock()
+	defer leakDetector.Unlock()
+
+	leaks := make([]RuntimeLeak, 0, len(leakDetector.created))
+	for _, stack := range leakDetector.created {
+		leaks = append(leaks, RuntimeLeak{Stack: stack})
+	}
+	return leaks
+}
+
+func trackRuntime(rt *Runtime) {
+	leakDetector.Lock()
+	defer leakDetector.Unlock()
+
+	if leakDetector.enabled {
+		leakDetector.created[rt] = callerStack(4)
+	}
+}
+
+func untrackRuntime(rt *Runtime) {
+	leakDetector.Lock()
+	defer leakDetector.Unlock()
+
+	delete(leakDetector.created, rt)
+}
+
+// Formats the stack of the calling goroutine, skipping the `skip` innermost frames.
+func callerStack(skip int) string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		sb.WriteString(frame.Function + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line) + "\n")
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
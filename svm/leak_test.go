@@ -0,0 +1,53 @@
+// +build cgo,!nosvm
+
+package svm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeakDetection(t *testing.T) {
+	api, err := Init()
+	assert.Nil(t, err)
+
+	EnableLeakDetection()
+	defer DisableLeakDetection()
+
+	count := api.RuntimesCount()
+	runtimes := []*Runtime{}
+	for i := 0; i < 3; i++ {
+		rt, err := api.NewRuntime(true, "")
+		assert.Nil(t, err)
+		runtimes = append(runtimes, rt)
+	}
+
+	assert.Nil(t, runtimes[0].Close())
+	assert.Nil(t, runtimes[0].Close())
+
+	leaks := LeakedRuntimes()
+	assert.Len(t, leaks, 2)
+	assert.Equal(t, api.RuntimesCount()-count, len(leaks))
+	for _, leak := range leaks {
+		assert.Contains(t, leak.Stack, "TestLeakDetection")
+		assert.Contains(t, leak.String(), "leak_test.go")
+	}
+
+	for _, rt := range runtimes {
+		assert.Nil(t, rt.Close())
+	}
+	assert.Empty(t, LeakedRuntimes())
+	assert.Equal(t, count, api.RuntimesCount())
+}
+
+func TestLeakDetectionDisabled(t *testing.T) {
+	api, err := Init()
+	assert.Nil(t, err)
+
+	rt, err := api.NewRuntime(true, "")
+	assert.Nil(t, err)
+	defer rt.Close()
+
+	assert.Empty(t, LeakedRuntimes())
+}
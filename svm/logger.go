@@ -0,0 +1,193 @@
+package svm
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The severity of a logged event.
+type LogLevel int
+
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+var logLevelNames = map[LogLevel]string{
+	LogDebug: "debug",
+	LogInfo:  "info",
+	LogWarn:  "warn",
+	LogError: "error",
+}
+
+func (level LogLevel) String() string {
+	if name, ok := logLevelNames[level]; ok {
+		return name
+	}
+	return "LogLevel(" + strconv.Itoa(int(level)) + ")"
+}
+
+// The keys of the structured fields attached to the logged events.
+const (
+	LogKeyOp        = "op"
+	LogKeyLayer     = "layer"
+	LogKeyTxId      = "tx_id"
+	LogKeyPrincipal = "principal"
+	LogKeyGasUsed   = "gas_used"
+	LogKeyErrorKind = "error_kind"
+	LogKeyError     = "error"
+	LogKeyState     = "state"
+)
+
+// A single structured field of a logged event.
+//
+// `Value` keeps its original type (e.g `Layer`, `TxId`, `Address` or `RuntimeErrorKind`),
+// so that a `Logger` adapter may render it as it sees fit.
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+// `Logger` receives the events logged by a `Runtime`, along with their structured fields.
+//
+// It's intended to be implemented by an adapter to the logging library of the embedding node.
+// Implementations must be safe for concurrent usage.
+//
+// The levels used are:
+//
+// * `LogDebug` - a layer has been opened, a transaction has been executed or a `Message` is invalid.
+// * `LogInfo` - a layer has been committed or rewinded, or a transaction has failed (i.e its receipt has `Success=false`).
+// * `LogWarn` - an operation has been rejected due to a misuse of the layer lifecycle (e.g `ErrLayerNotOpen`).
+// * `LogError` - executing a transaction (or committing / rewinding) has returned any other `error`.
+type Logger interface {
+	Log(level LogLevel, msg string, fields ...LogField)
+}
+
+// Sets the `Logger` of the runtimes created by `NewRuntime` from now on (including the ones of a `RuntimePool`).
+//
+// It should be called before any `Runtime` is created. Nothing is logged by default (or given a `nil` `logger`).
+func (api *API) SetLogger(logger Logger) {
+	api.logger = logger
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Log(level LogLevel, msg string, fields ...LogField) {}
+
+func loggerOrNoop(logger Logger) Logger {
+	if logger == nil {
+		return noopLogger{}
+	}
+	return logger
+}
+
+// Logs the outcome of a completed `op` (see `Logger` for the levels used).
+//
+// `env` and `ctx` are `nil` for the validation of a `Message`.
+func logOp(logger Logger, op Op, env *Envelope, ctx *Context, receipt interface{}, err error) {
+	fields := []LogField{{LogKeyOp, op}}
+	if ctx != nil {
+		fields = append(fields, LogField{LogKeyLayer, ctx.Layer}, LogField{LogKeyTxId, ctx.TxId})
+	}
+	if env != nil {
+		fields = append(fields, LogField{LogKeyPrincipal, env.Principal})
+	}
+
+	if err != nil {
+		fields = append(fields, LogField{LogKeyError, err})
+		if env == nil {
+			logger.Log(LogDebug, "invalid message", fields...)
+		} else {
+			logger.Log(logErrorLevel(err), "transaction execution error", fields...)
+		}
+		return
+	}
+
+	gas, rtErr, success, ok := receiptOutcome(receipt)
+	if !ok {
+		return
+	}
+
+	fields = append(fields, LogField{LogKeyGasUsed, gas})
+	if success {
+		logger.Log(LogDebug, "transaction executed", fields...)
+		return
+	}
+
+	if rtErr != nil {
+		fields = append(fields, LogField{LogKeyErrorKind, rtErr.Kind}, LogField{LogKeyError, rtErr})
+	}
+	logger.Log(LogInfo, "transaction failed", fields...)
+}
+
+// Returns `LogWarn` for the errors caused by the caller (misusing the layer lifecycle), and `LogError` otherwise.
+func logErrorLevel(err error) LogLevel {
+	if errors.Is(err, ErrLayerNotOpen) || errors.Is(err, ErrLayerAlreadyOpen) || errors.Is(err, ErrUnexpectedLayer) {
+		return LogWarn
+	}
+	return LogError
+}
+
+// `TextLogger` is a basic `Logger` writing each event (at or above its minimum level) as a single `logfmt` line.
+//
+// For example: `level=info msg="layer committed" layer=10 state=ab01...`
+type TextLogger struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level LogLevel
+}
+
+var _ Logger = (*TextLogger)(nil)
+
+func NewTextLogger(out io.Writer, level LogLevel) *TextLogger {
+	return &TextLogger{out: out, level: level}
+}
+
+func (l *TextLogger) Log(level LogLevel, msg string, fields ...LogField) {
+	if level < l.level {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("level=" + level.String() + " msg=" + strconv.Quote(msg))
+	for _, field := range fields {
+		sb.WriteString(" " + field.Key + "=" + formatLogValue(field.Value))
+	}
+	sb.WriteString("\n")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	io.WriteString(l.out, sb.String())
+}
+
+func formatLogValue(value interface{}) string {
+	var s string
+
+	switch v := value.(type) {
+	case Address:
+		return hex.EncodeToString(v[:])
+	case TemplateAddr:
+		return hex.EncodeToString(v[:])
+	case TxId:
+		return hex.EncodeToString(v[:])
+	case State:
+		return hex.EncodeToString(v[:])
+	case error:
+		s = v.Error()
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	if strings.ContainsAny(s, " =\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
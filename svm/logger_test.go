@@ -0,0 +1,119 @@
+package svm
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type loggedEvent struct {
+	level  LogLevel
+	msg    string
+	fields map[string]interface{}
+}
+
+// Records the logged events, so that tests can assert against them.
+type recordingLogger struct {
+	mu     sync.Mutex
+	events []loggedEvent
+}
+
+func (l *recordingLogger) Log(level LogLevel, msg string, fields ...LogField) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	event := loggedEvent{level: level, msg: msg, fields: make(map[string]interface{})}
+	for _, field := range fields {
+		event.fields[field.Key] = field.Value
+	}
+	l.events = append(l.events, event)
+}
+
+func (l *recordingLogger) find(msg string) []loggedEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	found := []loggedEvent{}
+	for _, event := range l.events {
+		if event.msg == msg {
+			found = append(found, event)
+		}
+	}
+	return found
+}
+
+func TestTextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewTextLogger(&buf, LogInfo)
+
+	logger.Log(LogDebug, "layer opened", LogField{LogKeyLayer, Layer(1)})
+	assert.Empty(t, buf.String())
+
+	logger.Log(LogInfo, "transaction failed",
+		LogField{LogKeyLayer, Layer(2)},
+		LogField{LogKeyTxId, TxId{0xAB}},
+		LogField{LogKeyErrorKind, OOG},
+		LogField{LogKeyError, errors.New("out of gas")},
+	)
+	expected := `level=info msg="transaction failed" layer=2 tx_id=ab` + string(bytes.Repeat([]byte("00"), TxIdLength-1)) +
+		` error_kind="out of gas" error="out of gas"` + "\n"
+	assert.Equal(t, expected, buf.String())
+
+	assert.Equal(t, "warn", LogWarn.String())
+	assert.Equal(t, "LogLevel(10)", LogLevel(10).String())
+}
+
+func TestFakeLogger(t *testing.T) {
+	logger := &recordingLogger{}
+
+	rt := NewFakeRuntime()
+	rt.GasUsed = Gas(10)
+	assert.Nil(t, rt.SetLogger(logger))
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)}))
+
+	_, err := rt.Deploy(NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000), GasFee(0)), []byte{0}, NewContext(Layer(1), TxId{}))
+	assert.True(t, errors.Is(err, ErrLayerNotOpen))
+
+	assert.Nil(t, rt.Open(Layer(1)))
+	fakeDeploy(t, rt)
+	fakeCall(t, rt, Address{0xFF}, "store_addr", Amount(0))
+
+	_, _, err = rt.Commit()
+	assert.Nil(t, err)
+	_, err = rt.Rewind(Layer(0))
+	assert.Nil(t, err)
+
+	events := logger.find("transaction execution error")
+	assert.Len(t, events, 1)
+	assert.Equal(t, LogWarn, events[0].level)
+
+	events = logger.find("layer opened")
+	assert.Len(t, events, 1)
+	assert.Equal(t, LogDebug, events[0].level)
+	assert.Equal(t, Layer(1), events[0].fields[LogKeyLayer])
+
+	events = logger.find("transaction executed")
+	assert.Len(t, events, 1)
+	assert.Equal(t, OpDeploy, events[0].fields[LogKeyOp])
+	assert.Equal(t, Gas(10), events[0].fields[LogKeyGasUsed])
+
+	events = logger.find("transaction failed")
+	assert.Len(t, events, 1)
+	assert.Equal(t, LogInfo, events[0].level)
+	assert.Equal(t, OpCall, events[0].fields[LogKeyOp])
+	assert.Equal(t, Layer(1), events[0].fields[LogKeyLayer])
+	assert.Equal(t, TxId{}, events[0].fields[LogKeyTxId])
+	assert.Equal(t, Address{}, events[0].fields[LogKeyPrincipal])
+	assert.Equal(t, AccountNotFound, events[0].fields[LogKeyErrorKind])
+
+	assert.Len(t, logger.find("layer committed"), 1)
+	assert.Len(t, logger.find("layer rewinded"), 1)
+
+	// silenced
+	assert.Nil(t, rt.SetLogger(nil))
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.Len(t, logger.find("layer opened"), 1)
+}
@@ -0,0 +1,185 @@
+package svm
+
+import "encoding/binary"
+
+// Encodes a binary `Call Message`.
+//
+// The output is byte-identical to the one emitted by `svm-cli tx --tx-type=call`.
+// Both `VerifyData` and `CallData` are expected to be already encoded.
+//
+// ```text
+//  +-----------+-------------+----------------+
+//  |           |             |                |
+//  |  Version  |   Target    |   Function     |
+//  |   (u16)   |  (Address)  |   (String)     |
+//  |           |             |                |
+//  +-----------+-------------+----------------+
+//  |              |                           |
+//  |  VerifyData  |        CallData           |
+//  |    (Blob)    |         (Blob)            |
+//  |              |                           |
+//  +--------------+---------------------------+
+// ```
+//
+// Returns `(nil, error)` when any of the variable-length fields is too long.
+func EncodeCallMessage(msg *CallMessage) ([]byte, error) {
+	bytes := make([]byte, 0, VersionLength+AddressLength+3+len(msg.FuncName)+len(msg.VerifyData)+len(msg.CallData))
+
+	bytes = encodeVersion(bytes, msg.Version)
+	bytes = encodeAddress(bytes, msg.Target)
+
+	bytes, err := encodeString(bytes, msg.FuncName)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err = encodeBlob(bytes, msg.VerifyData)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeBlob(bytes, msg.CallData)
+}
+
+func NewCallMessage(version uint16, target Address, funcName string, verifyData []byte, callData []byte) *CallMessage {
+	return &CallMessage{
+		Version:    version,
+		Target:     target,
+		FuncName:   funcName,
+		VerifyData: verifyData,
+		CallData:   callData,
+	}
+}
+
+// Encodes a binary `Spawn Message`.
+//
+// The output is byte-identical to the one emitted by `svm-cli tx --tx-type=spawn`.
+// The `CallData` is expected to be already encoded.
+//
+// ```text
+//  +-----------+-------------+----------------+
+//  |           |             |                |
+//  |  Version  |  Template   |      Name      |
+//  |   (u16)   |  (Address)  |   (String)     |
+//  |           |             |                |
+//  +-----------+-------------+----------------+
+//  |              |                           |
+//  |     Ctor     |        CallData           |
+//  |   (String)   |         (Blob)            |
+//  |              |                           |
+//  +--------------+---------------------------+
+// ```
+//
+// Returns `(nil, error)` when any of the variable-length fields is too long.
+func EncodeSpawnMessage(msg *SpawnMessage) ([]byte, error) {
+	bytes := make([]byte, 0, VersionLength+AddressLength+3+len(msg.Name)+len(msg.CtorName)+len(msg.CallData))
+
+	bytes = encodeVersion(bytes, msg.Version)
+	bytes = encodeAddress(bytes, msg.Template)
+
+	bytes, err := encodeString(bytes, msg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err = encodeString(bytes, msg.CtorName)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeBlob(bytes, msg.CallData)
+}
+
+func NewSpawnMessage(version uint16, template TemplateAddr, name string, ctorName string, callData []byte) *SpawnMessage {
+	return &SpawnMessage{
+		Version:  version,
+		Template: template,
+		Name:     name,
+		CtorName: ctorName,
+		CallData: callData,
+	}
+}
+
+func decodeCallMessage(bytes []byte) (*CallMessage, error) {
+	msg, err := decodeCallMessageFields(bytes)
+	if err != nil {
+		return nil, withDecodeSubject(err, "Call Message")
+	}
+	return msg, nil
+}
+
+func decodeCallMessageFields(bytes []byte) (*CallMessage, error) {
+	msg := &CallMessage{}
+
+	version, bytes, err := decodeMessageVersion(bytes)
+	if err != nil {
+		return nil, err
+	}
+	msg.Version = version
+
+	if msg.Target, bytes, err = decodeReceiptAddress(bytes); err != nil {
+		return nil, err
+	}
+	if msg.FuncName, bytes, err = decodeString(bytes); err != nil {
+		return nil, err
+	}
+	if msg.VerifyData, bytes, err = decodeBlob(bytes); err != nil {
+		return nil, err
+	}
+	if msg.CallData, bytes, err = decodeBlob(bytes); err != nil {
+		return nil, err
+	}
+	if len(bytes) != 0 {
+		return nil, newDecodeError("unexpected trailing bytes")
+	}
+
+	return msg, nil
+}
+
+func decodeSpawnMessage(bytes []byte) (*SpawnMessage, error) {
+	msg, err := decodeSpawnMessageFields(bytes)
+	if err != nil {
+		return nil, withDecodeSubject(err, "Spawn Message")
+	}
+	return msg, nil
+}
+
+func decodeSpawnMessageFields(bytes []byte) (*SpawnMessage, error) {
+	msg := &SpawnMessage{}
+
+	version, bytes, err := decodeMessageVersion(bytes)
+	if err != nil {
+		return nil, err
+	}
+	msg.Version = version
+
+	if msg.Template, bytes, err = decodeReceiptAddress(bytes); err != nil {
+		return nil, err
+	}
+	if msg.Name, bytes, err = decodeString(bytes); err != nil {
+		return nil, err
+	}
+	if msg.CtorName, bytes, err = decodeString(bytes); err != nil {
+		return nil, err
+	}
+	if msg.CallData, bytes, err = decodeBlob(bytes); err != nil {
+		return nil, err
+	}
+	if len(bytes) != 0 {
+		return nil, newDecodeError("unexpected trailing bytes")
+	}
+
+	return msg, nil
+}
+
+func decodeMessageVersion(bytes []byte) (uint16, []byte, error) {
+	if err := ensureLength(bytes, VersionLength, "version"); err != nil {
+		return 0, nil, err
+	}
+	return binary.BigEndian.Uint16(bytes), bytes[VersionLength:], nil
+}
+
+func decodeBlob(bytes []byte) ([]byte, []byte, error) {
+	s, bytes, err := decodeString(bytes)
+	return []byte(s), bytes, err
+}
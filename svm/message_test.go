@@ -0,0 +1,98 @@
+package svm
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hexToAddress(t *testing.T, s string) Address {
+	var addr Address
+	bytes, err := hex.DecodeString(s)
+	assert.Nil(t, err)
+	copy(addr[:], bytes)
+	return addr
+}
+
+func TestEncodeCallMessage(t *testing.T) {
+	// equivalent to `inputs/call/store_addr.json`
+	target := hexToAddress(t, "066818abe361dd44f425da19e17c45babc40e232")
+	stored := hexToAddress(t, "102030405060708090102030405060708090AABB")
+	calldata := append([]byte{0x40}, stored[:]...)
+	msg := NewCallMessage(0, target, "store_addr", []byte{}, calldata)
+
+	bytes, err := EncodeCallMessage(msg)
+	assert.Nil(t, err)
+
+	// the golden vector emitted by `svm-cli` (see `inputs/generate_txs.sh`)
+	expected := readFile(t, "inputs/call/store_addr.json.bin")
+	assert.Equal(t, expected, bytes)
+}
+
+func TestEncodeCallMessageEmptyData(t *testing.T) {
+	msg := NewCallMessage(0, Address{0xAB}, "load_addr", nil, nil)
+
+	bytes, err := EncodeCallMessage(msg)
+	assert.Nil(t, err)
+	assert.Len(t, bytes, VersionLength+AddressLength+1+len("load_addr")+1+1)
+	assert.Equal(t, []byte{0, 0}, bytes[len(bytes)-2:])
+}
+
+func TestEncodeCallMessageTooLong(t *testing.T) {
+	msg := NewCallMessage(0, Address{}, strings.Repeat("a", MaxStringLength+1), nil, nil)
+	_, err := EncodeCallMessage(msg)
+	assert.NotNil(t, err)
+
+	msg = NewCallMessage(0, Address{}, "store_addr", nil, make([]byte, MaxBlobLength+1))
+	_, err = EncodeCallMessage(msg)
+	assert.NotNil(t, err)
+}
+
+func TestEncodeSpawnMessage(t *testing.T) {
+	// equivalent to `inputs/spawn/initialize.json`
+	template := TemplateAddr(hexToAddress(t, "b5eba98957e6a93173ffb50207cceeedfddb1a72"))
+	owner := hexToAddress(t, "8f20ed1a0e342c2a75b1b3f8014545dd3d886078")
+	calldata := append([]byte{0x40}, owner[:]...)
+	calldata = append(calldata, 0x10)
+	msg := NewSpawnMessage(0, template, "My Account", "initialize", calldata)
+
+	bytes, err := EncodeSpawnMessage(msg)
+	assert.Nil(t, err)
+
+	// the golden vector emitted by `svm-cli` (see `inputs/generate_txs.sh`)
+	expected := readFile(t, "inputs/spawn/initialize.json.bin")
+	assert.Equal(t, expected, bytes)
+}
+
+func TestEncodeSpawnMessageTooLong(t *testing.T) {
+	msg := NewSpawnMessage(0, TemplateAddr{}, strings.Repeat("a", MaxStringLength+1), "initialize", nil)
+	_, err := EncodeSpawnMessage(msg)
+	assert.NotNil(t, err)
+
+	msg = NewSpawnMessage(0, TemplateAddr{}, "My Account", strings.Repeat("a", MaxStringLength+1), nil)
+	_, err = EncodeSpawnMessage(msg)
+	assert.NotNil(t, err)
+
+	msg = NewSpawnMessage(0, TemplateAddr{}, "My Account", "initialize", make([]byte, MaxBlobLength+1))
+	_, err = EncodeSpawnMessage(msg)
+	assert.NotNil(t, err)
+}
+
+func TestDecodeMessageCorrupted(t *testing.T) {
+	call, err := EncodeCallMessage(NewCallMessage(0, Address{0x01}, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	_, err = decodeCallMessage(call[:len(call)-1])
+
+	var decodeErr *DecodeError
+	assert.True(t, errors.As(err, &decodeErr))
+	assert.Equal(t, "Call Message", decodeErr.Subject)
+	assert.Contains(t, err.Error(), "Received a corrupted Call Message")
+
+	spawn, err := EncodeSpawnMessage(NewSpawnMessage(0, TemplateAddr{}, "name", "ctor", nil))
+	assert.Nil(t, err)
+	_, err = decodeSpawnMessage(append(spawn, 0))
+	assert.EqualError(t, err, "Received a corrupted Spawn Message: unexpected trailing bytes")
+}
@@ -0,0 +1,280 @@
+package svm
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The operations whose latency and outcome are reported to `Metrics`.
+type Op int
+
+const (
+	OpDeploy Op = iota
+	OpSpawn
+	OpCall
+	OpVerify
+	OpValidateDeploy
+	OpValidateSpawn
+	OpValidateCall
+)
+
+var opNames = map[Op]string{
+	OpDeploy:         "deploy",
+	OpSpawn:          "spawn",
+	OpCall:           "call",
+	OpVerify:         "verify",
+	OpValidateDeploy: "validate_deploy",
+	OpValidateSpawn:  "validate_spawn",
+	OpValidateCall:   "validate_call",
+}
+
+func (op Op) String() string {
+	if name, ok := opNames[op]; ok {
+		return name
+	}
+	return "Op(" + strconv.Itoa(int(op)) + ")"
+}
+
+// `Metrics` is the hook through which a `Runtime` reports what it does (e.g to a node's metrics registry).
+//
+// Implementations must be safe for concurrent usage and are expected to return promptly,
+// since they are called while the `Runtime` is held.
+//
+// # Notes
+//
+// * `Deploy`, `Spawn` and `Call` are reported under the same `Op` as their `*Context` variants.
+//   Each of `ExecuteCall` and `ExecuteLayer` reports the transactions it executes.
+//   A transaction abandoned by a `*Context` variant is reported once it completes (along with `goCtx.Err()`).
+//
+// * Simulations (i.e `EstimateGas` and the `Simulate*` methods) aren't reported.
+type Metrics interface {
+	// Reports a completed `op` and its latency. `err` is the returned `error` (if any).
+	ObserveOp(op Op, latency time.Duration, err error)
+
+	// Reports the `Gas` used by a transaction (whether it has succeeded or not).
+	ObserveGasUsed(op Op, gas Gas)
+
+	// Reports a transaction whose receipt has `Success=false`.
+	ObserveFailure(op Op, kind RuntimeErrorKind)
+
+	// Reports a committed `layer`.
+	ObserveCommit(layer Layer)
+
+	// Reports a rewind back to `layer`.
+	ObserveRewind(layer Layer)
+
+	// Reports a new `Runtime`, and the release of one (so that the live runtimes can be tracked).
+	RuntimeCreated()
+	RuntimeClosed()
+}
+
+// Sets the `Metrics` of the runtimes created by `NewRuntime` from now on (including the ones of a `RuntimePool`).
+//
+// It should be called before any `Runtime` is created. A `nil` `metrics` disables the reporting.
+func (api *API) SetMetrics(metrics Metrics) {
+	api.metrics = metrics
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveOp(op Op, latency time.Duration, err error) {}
+func (noopMetrics) ObserveGasUsed(op Op, gas Gas)                     {}
+func (noopMetrics) ObserveFailure(op Op, kind RuntimeErrorKind)       {}
+func (noopMetrics) ObserveCommit(layer Layer)                         {}
+func (noopMetrics) ObserveRewind(layer Layer)                         {}
+func (noopMetrics) RuntimeCreated()                                   {}
+func (noopMetrics) RuntimeClosed()                                    {}
+
+func metricsOrNoop(metrics Metrics) Metrics {
+	if metrics == nil {
+		return noopMetrics{}
+	}
+	return metrics
+}
+
+// Reports a completed `op` to `metrics`, along with the outcome of the receipt it has returned (if any).
+func observeOp(metrics Metrics, op Op, start time.Time, receipt interface{}, err error) {
+	metrics.ObserveOp(op, time.Since(start), err)
+	if err != nil {
+		return
+	}
+
+	gas, rtErr, success, ok := receiptOutcome(receipt)
+	if !ok {
+		return
+	}
+
+	metrics.ObserveGasUsed(op, gas)
+	if !success && rtErr != nil {
+		metrics.ObserveFailure(op, rtErr.Kind)
+	}
+}
+
+// Extracts the outcome of a `*DeployReceipt`, `*SpawnReceipt` or `*CallReceipt`.
+// When `receipt` is none of these (or is `nil`) - `ok` is set to `false`.
+func receiptOutcome(receipt interface{}) (gas Gas, rtErr *RuntimeError, success bool, ok bool) {
+	switch r := receipt.(type) {
+	case *DeployReceipt:
+		if r != nil {
+			return r.GasUsed, r.Error, r.Success, true
+		}
+	case *SpawnReceipt:
+		if r != nil {
+			return r.GasUsed, r.Error, r.Success, true
+		}
+	case *CallReceipt:
+		if r != nil {
+			return r.GasUsed, r.Error, r.Success, true
+		}
+	}
+	return Gas(0), nil, false, false
+}
+
+// The figures recorded by `InMemoryMetrics` for a single `Op`.
+type OpStats struct {
+	Count  int
+	Errors int // the number of times an `error` has been returned
+
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+
+	GasUsed  Gas
+	Failures map[RuntimeErrorKind]int
+}
+
+// `InMemoryMetrics` is a reference implementation of `Metrics`, which keeps all the figures in memory.
+//
+// It's mostly intended for tests asserting what a `Runtime` has reported,
+// and as an example for implementing an adapter to an actual metrics library.
+type InMemoryMetrics struct {
+	mu sync.Mutex
+
+	ops          map[Op]*OpStats
+	commits      int
+	rewinds      int
+	lastCommit   Layer
+	liveRuntimes int
+}
+
+var _ Metrics = (*InMemoryMetrics)(nil)
+
+func NewInMemoryMetrics() *InMemoryMetrics {
+	return &InMemoryMetrics{ops: make(map[Op]*OpStats)}
+}
+
+func (m *InMemoryMetrics) ObserveOp(op Op, latency time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.stats(op)
+	stats.Count++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.TotalLatency += latency
+	if latency > stats.MaxLatency {
+		stats.MaxLatency = latency
+	}
+}
+
+func (m *InMemoryMetrics) ObserveGasUsed(op Op, gas Gas) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stats(op).GasUsed += gas
+}
+
+func (m *InMemoryMetrics) ObserveFailure(op Op, kind RuntimeErrorKind) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stats(op).Failures[kind]++
+}
+
+func (m *InMemoryMetrics) ObserveCommit(layer Layer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.commits++
+	m.lastCommit = layer
+}
+
+func (m *InMemoryMetrics) ObserveRewind(layer Layer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rewinds++
+}
+
+func (m *InMemoryMetrics) RuntimeCreated() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.liveRuntimes++
+}
+
+func (m *InMemoryMetrics) RuntimeClosed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.liveRuntimes--
+}
+
+// Must be called while holding `m.mu`.
+func (m *InMemoryMetrics) stats(op Op) *OpStats {
+	stats, ok := m.ops[op]
+	if !ok {
+		stats = &OpStats{Failures: make(map[RuntimeErrorKind]int)}
+		m.ops[op] = stats
+	}
+	return stats
+}
+
+// Returns a copy of the figures recorded for `op`.
+func (m *InMemoryMetrics) Op(op Op) OpStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := *m.stats(op)
+	stats.Failures = make(map[RuntimeErrorKind]int, len(stats.Failures))
+	for kind, count := range m.ops[op].Failures {
+		stats.Failures[kind] = count
+	}
+	return stats
+}
+
+// Returns the number of transactions (of any `Op`) which have failed with `kind`.
+func (m *InMemoryMetrics) Failures(kind RuntimeErrorKind) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, stats := range m.ops {
+		count += stats.Failures[kind]
+	}
+	return count
+}
+
+// Returns the number of commits, along with the last committed layer.
+func (m *InMemoryMetrics) Commits() (int, Layer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.commits, m.lastCommit
+}
+
+func (m *InMemoryMetrics) Rewinds() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.rewinds
+}
+
+// Returns the number of runtimes which have been created but not closed yet.
+func (m *InMemoryMetrics) LiveRuntimes() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.liveRuntimes
+}
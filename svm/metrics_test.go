@@ -0,0 +1,104 @@
+package svm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryMetrics(t *testing.T) {
+	m := NewInMemoryMetrics()
+
+	m.ObserveOp(OpCall, 2*time.Millisecond, nil)
+	m.ObserveOp(OpCall, 5*time.Millisecond, errors.New("failed"))
+	m.ObserveGasUsed(OpCall, Gas(100))
+	m.ObserveGasUsed(OpCall, Gas(20))
+	m.ObserveFailure(OpCall, OOG)
+	m.ObserveFailure(OpSpawn, OOG)
+	m.ObserveFailure(OpSpawn, TemplateNotFound)
+
+	stats := m.Op(OpCall)
+	assert.Equal(t, 2, stats.Count)
+	assert.Equal(t, 1, stats.Errors)
+	assert.Equal(t, 7*time.Millisecond, stats.TotalLatency)
+	assert.Equal(t, 5*time.Millisecond, stats.MaxLatency)
+	assert.Equal(t, Gas(120), stats.GasUsed)
+	assert.Equal(t, map[RuntimeErrorKind]int{OOG: 1}, stats.Failures)
+
+	// the returned stats are a copy
+	stats.Failures[OOG] = 10
+	assert.Equal(t, 1, m.Op(OpCall).Failures[OOG])
+
+	assert.Equal(t, 2, m.Failures(OOG))
+	assert.Equal(t, 1, m.Failures(TemplateNotFound))
+	assert.Equal(t, 0, m.Op(OpDeploy).Count)
+
+	m.ObserveCommit(Layer(1))
+	m.ObserveCommit(Layer(2))
+	m.ObserveRewind(Layer(1))
+	commits, last := m.Commits()
+	assert.Equal(t, 2, commits)
+	assert.Equal(t, Layer(2), last)
+	assert.Equal(t, 1, m.Rewinds())
+
+	m.RuntimeCreated()
+	m.RuntimeCreated()
+	m.RuntimeClosed()
+	assert.Equal(t, 1, m.LiveRuntimes())
+
+	assert.Equal(t, "validate_call", OpValidateCall.String())
+	assert.Equal(t, "Op(100)", Op(100).String())
+}
+
+func TestFakeMetrics(t *testing.T) {
+	m := NewInMemoryMetrics()
+
+	rt := NewFakeRuntime()
+	rt.GasUsed = Gas(10)
+	assert.Nil(t, rt.SetMetrics(m))
+	assert.Equal(t, 1, m.LiveRuntimes())
+
+	_, err := rt.ValidateCall([]byte{0, 0, 0, 0})
+	assert.NotNil(t, err)
+
+	// executing outside of an open layer
+	_, err = rt.Deploy(NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000), GasFee(0)), []byte{0}, NewContext(Layer(1), TxId{}))
+	assert.True(t, errors.Is(err, ErrLayerNotOpen))
+
+	assert.Nil(t, rt.Open(Layer(1)))
+	spawned := fakeSpawn(t, rt, fakeDeploy(t, rt), "initialize", Amount(0))
+	assert.True(t, spawned.Success)
+	assert.True(t, fakeCall(t, rt, spawned.AccountAddr, "store_addr", Amount(0)).Success)
+	assert.False(t, fakeCall(t, rt, Address{0xFF}, "store_addr", Amount(0)).Success)
+
+	_, _, err = rt.Commit()
+	assert.Nil(t, err)
+	_, err = rt.Rewind(Layer(0))
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, m.Op(OpValidateCall).Errors)
+	assert.Equal(t, OpStats{Count: 2, Errors: 1, GasUsed: Gas(10)}, withoutLatency(m.Op(OpDeploy)))
+	assert.Equal(t, 1, m.Op(OpSpawn).Count)
+	assert.Equal(t, 2, m.Op(OpCall).Count)
+	assert.Equal(t, Gas(10), m.Op(OpCall).GasUsed)
+	assert.Equal(t, 1, m.Failures(AccountNotFound))
+
+	commits, last := m.Commits()
+	assert.Equal(t, 1, commits)
+	assert.Equal(t, Layer(1), last)
+	assert.Equal(t, 1, m.Rewinds())
+
+	rt.Destroy()
+	rt.Destroy()
+	assert.Equal(t, 0, m.LiveRuntimes())
+}
+
+func withoutLatency(stats OpStats) OpStats {
+	stats.TotalLatency, stats.MaxLatency = 0, 0
+	if len(stats.Failures) == 0 {
+		stats.Failures = nil
+	}
+	return stats
+}
@@ -0,0 +1,189 @@
+package svm
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+)
+
+// Returned when using a `RuntimePool` after it has been closed.
+var ErrPoolClosed = errors.New("runtime pool has been closed")
+
+// Returned when releasing a `Runtime` that isn't currently acquired from the `RuntimePool`
+// (i.e it has been already released, or it belongs to another pool).
+var ErrRuntimeNotAcquired = errors.New("runtime hasn't been acquired from the pool")
+
+// `RuntimePool` hands out in-memory `Runtime`s to concurrent goroutines.
+//
+// It's intended for the stateless validation of messages (i.e `ValidateDeploy`, `ValidateSpawn` and `ValidateCall`),
+// so that concurrent validations don't contend on a single `Runtime`.
+// At most `size` validations run in parallel, the others block until a `Runtime` is released back to the pool.
+//
+// A `RuntimePool` is safe for concurrent usage.
+type RuntimePool struct {
+	size     int
+	runtimes chan *Runtime
+
+	// The runtimes currently taken out of the pool (via `Acquire`).
+	mu       sync.Mutex
+	acquired map[*Runtime]bool
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// Creates a `RuntimePool` holding `size` in-memory runtimes (created via `NewRuntime(true, "")`).
+//
+// On failure, the runtimes created so far are destroyed and `(nil, error)` is returned.
+func (api *API) NewRuntimePool(size int) (*RuntimePool, error) {
+	if size <= 0 {
+		return nil, errors.New("invalid `RuntimePool` size " + strconv.Itoa(size))
+	}
+
+	pool := &RuntimePool{
+		size:     size,
+		runtimes: make(chan *Runtime, size),
+		closed:   make(chan struct{}),
+		acquired: make(map[*Runtime]bool, size),
+	}
+
+	for i := 0; i < size; i++ {
+		rt, err := api.NewRuntime(true, "")
+		if err != nil {
+			close(pool.runtimes)
+			for rt := range pool.runtimes {
+				rt.Destroy()
+			}
+			return nil, err
+		}
+		pool.runtimes <- rt
+	}
+
+	return pool, nil
+}
+
+func (p *RuntimePool) Size() int {
+	return p.size
+}
+
+// Takes a `Runtime` out of the pool, blocking until one is available.
+//
+// The `Runtime` must be given back via `Release` (and must not be destroyed by the caller).
+// Returns `ErrPoolClosed` once the pool has been closed.
+func (p *RuntimePool) Acquire() (*Runtime, error) {
+	select {
+	case <-p.closed:
+		return nil, ErrPoolClosed
+	case rt := <-p.runtimes:
+		select {
+		case <-p.closed:
+			p.runtimes <- rt
+			return nil, ErrPoolClosed
+		default:
+			p.mu.Lock()
+			p.acquired[rt] = true
+			p.mu.Unlock()
+			return rt, nil
+		}
+	}
+}
+
+// Gives back a `Runtime` previously taken via `Acquire`.
+//
+// Returns `ErrRuntimeNotAcquired` (leaving the pool untouched) when `rt` isn't currently acquired from the pool,
+// e.g when it's released twice.
+func (p *RuntimePool) Release(rt *Runtime) error {
+	p.mu.Lock()
+	if !p.acquired[rt] {
+		p.mu.Unlock()
+		return ErrRuntimeNotAcquired
+	}
+	delete(p.acquired, rt)
+	p.mu.Unlock()
+
+	p.runtimes <- rt
+	return nil
+}
+
+// Validates a `Deploy Message` using one of the pool runtimes (see `Runtime.ValidateDeploy`).
+func (p *RuntimePool) ValidateDeploy(msg []byte) (bool, error) {
+	return p.Validate(DeployType, msg)
+}
+
+// Validates a `Spawn Message` using one of the pool runtimes (see `Runtime.ValidateSpawn`).
+func (p *RuntimePool) ValidateSpawn(msg []byte) (bool, error) {
+	return p.Validate(SpawnType, msg)
+}
+
+// Validates a `Call Message` using one of the pool runtimes (see `Runtime.ValidateCall`).
+func (p *RuntimePool) ValidateCall(msg []byte) (bool, error) {
+	return p.Validate(CallType, msg)
+}
+
+// Validates a `Message` of the given `txType` using one of the pool runtimes.
+func (p *RuntimePool) Validate(txType TxType, msg []byte) (bool, error) {
+	rt, err := p.Acquire()
+	if err != nil {
+		return false, err
+	}
+	defer p.Release(rt)
+
+	switch txType {
+	case DeployType:
+		return rt.ValidateDeploy(msg)
+	case SpawnType:
+		return rt.ValidateSpawn(msg)
+	case CallType:
+		return rt.ValidateCall(msg)
+	default:
+		return false, errors.New("unknown transaction `Type` " + strconv.Itoa(int(txType)))
+	}
+}
+
+// Validates `msgs` (all of the given `txType`) in parallel, using up to `Size` goroutines.
+//
+// Returns the validation `error` of each `Message` (`nil` for a valid one), in the order of `msgs`.
+func (p *RuntimePool) ValidateBatch(txType TxType, msgs [][]byte) []error {
+	errs := make([]error, len(msgs))
+
+	workers := p.size
+	if len(msgs) < workers {
+		workers = len(msgs)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				_, errs[i] = p.Validate(txType, msgs[i])
+			}
+		}()
+	}
+
+	for i := range msgs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
+
+// Closes the pool and destroys its runtimes.
+//
+// Blocks until all the acquired runtimes have been released. Calling `Close` more than once is a no-op.
+// Once closed, any pending or future `Acquire` returns `ErrPoolClosed`.
+func (p *RuntimePool) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+
+		for i := 0; i < p.size; i++ {
+			rt := <-p.runtimes
+			rt.Destroy()
+		}
+	})
+	return nil
+}
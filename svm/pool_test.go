@@ -0,0 +1,126 @@
+// +build cgo,!nosvm
+
+package svm
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func poolSetup(t *testing.T, size int) (*API, *RuntimePool) {
+	api, err := Init()
+	assert.Nil(t, err)
+
+	pool, err := api.NewRuntimePool(size)
+	assert.Nil(t, err)
+	assert.Equal(t, size, pool.Size())
+
+	return api, pool
+}
+
+func TestRuntimePoolValidate(t *testing.T) {
+	_, pool := poolSetup(t, 2)
+	defer pool.Close()
+
+	valid, err := pool.ValidateDeploy(readFile(t, "inputs/template_example.svm"))
+	assert.True(t, valid)
+	assert.Nil(t, err)
+
+	var validateErr *ValidateError
+	for _, validate := range []func([]byte) (bool, error){pool.ValidateDeploy, pool.ValidateSpawn, pool.ValidateCall} {
+		valid, err = validate([]byte{0, 0, 0, 0})
+		assert.False(t, valid)
+		assert.True(t, errors.As(err, &validateErr))
+	}
+}
+
+func TestRuntimePoolConcurrent(t *testing.T) {
+	api, pool := poolSetup(t, 4)
+	count := api.RuntimesCount()
+
+	valid := readFile(t, "inputs/template_example.svm")
+	msgs := [][]byte{}
+	for i := 0; i < 50; i++ {
+		msgs = append(msgs, valid, []byte{0, 0, 0, 0})
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			errs := pool.ValidateBatch(DeployType, msgs)
+			assert.Len(t, errs, len(msgs))
+			for i, err := range errs {
+				assert.Equal(t, i%2 == 1, err != nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Nil(t, pool.Close())
+	assert.Equal(t, count-4, api.RuntimesCount())
+}
+
+func TestRuntimePoolClose(t *testing.T) {
+	api, pool := poolSetup(t, 1)
+	count := api.RuntimesCount()
+
+	rt, err := pool.Acquire()
+	assert.Nil(t, err)
+
+	// blocked until the pool is closed
+	acquired := make(chan error)
+	go func() {
+		_, err := pool.Acquire()
+		acquired <- err
+	}()
+
+	closed := make(chan struct{})
+	go func() {
+		pool.Close()
+		close(closed)
+	}()
+
+	assert.True(t, errors.Is(<-acquired, ErrPoolClosed))
+	assert.Nil(t, pool.Release(rt))
+	<-closed
+
+	assert.Equal(t, count-1, api.RuntimesCount())
+	assert.Nil(t, pool.Close())
+
+	_, err = pool.ValidateCall([]byte{0, 0, 0, 1})
+	assert.True(t, errors.Is(err, ErrPoolClosed))
+}
+
+func TestRuntimePoolRelease(t *testing.T) {
+	api, pool := poolSetup(t, 1)
+	defer pool.Close()
+
+	rt, err := pool.Acquire()
+	assert.Nil(t, err)
+	assert.Nil(t, pool.Release(rt))
+
+	// releasing twice
+	assert.True(t, errors.Is(pool.Release(rt), ErrRuntimeNotAcquired))
+
+	// releasing a `Runtime` not taken out of the pool
+	foreign, err := api.NewRuntime(true, "")
+	assert.Nil(t, err)
+	defer foreign.Destroy()
+	assert.True(t, errors.Is(pool.Release(foreign), ErrRuntimeNotAcquired))
+
+	// the pool is left untouched
+	acquired, err := pool.Acquire()
+	assert.Nil(t, err)
+	assert.Equal(t, rt, acquired)
+	assert.Nil(t, pool.Release(acquired))
+
+	valid, err := pool.ValidateDeploy(readFile(t, "inputs/template_example.svm"))
+	assert.True(t, valid)
+	assert.Nil(t, err)
+}
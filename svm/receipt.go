@@ -2,7 +2,8 @@ package svm
 
 import (
 	"encoding/binary"
-	"log"
+	"errors"
+	"strconv"
 )
 
 // * One byte for `tx type`
@@ -10,9 +11,51 @@ import (
 // * One byte for `success`
 const ReceiptHeaderLength = 1 + 2 + 1
 
+// Decodes a binary `Receipt` (as returned by `SVM` or by the `Encode*Receipt` functions).
+//
+// On success returns either a `*DeployReceipt`, `*SpawnReceipt` or `*CallReceipt`.
+// On failure returns `(nil, error)` where `error` is a `*DecodeError`.
+func DecodeReceipt(bytes []byte) (interface{}, error) {
+	return decodeReceipt(bytes)
+}
+
+// Decodes the body of a binary `Receipt` (i.e the bytes following its header).
+type receiptDecoder func(txType TxType, success bool, bytes []byte) (interface{}, error)
+
+// Holds the `receiptDecoder` of each supported `ReceiptVersion`.
+// Supporting a new `Receipt` format means registering its `receiptDecoder` here.
+var receiptDecoders = map[ReceiptVersion]receiptDecoder{
+	0: decodeReceiptV0,
+}
+
 func decodeReceipt(bytes []byte) (interface{}, error) {
-	txType, success, bytes := decodeReceiptHeader(bytes)
+	txType, version, success, bytes, err := decodeReceiptHeader(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, ok := receiptDecoders[version]
+	if !ok {
+		return nil, newDecodeError("unsupported `version` " + strconv.Itoa(int(version)))
+	}
 
+	receipt, err := decoder(txType, success, bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r := receipt.(type) {
+	case *DeployReceipt:
+		r.Version = version
+	case *SpawnReceipt:
+		r.Version = version
+	case *CallReceipt:
+		r.Version = version
+	}
+	return receipt, nil
+}
+
+func decodeReceiptV0(txType TxType, success bool, bytes []byte) (interface{}, error) {
 	if success {
 		return decodeSuccess(txType, bytes)
 	}
@@ -36,7 +79,7 @@ func decodeFailure(txType TxType, bytes []byte) (interface{}, error) {
 		receipt := &CallReceipt{Success: false, Error: rtError, Logs: logs}
 		return receipt, nil
 	default:
-		panic("Unreachable")
+		return nil, newDecodeError("unknown `tx type`")
 	}
 }
 
@@ -49,79 +92,86 @@ func decodeSuccess(txType TxType, bytes []byte) (interface{}, error) {
 	case TxType(CallType):
 		return decodeCallReceipt(bytes)
 	default:
-		panic("Unreachable")
+		return nil, newDecodeError("unknown `tx type`")
 	}
 }
 
 func decodeRuntimeError(bytes []byte) (*RuntimeError, []Log, error) {
-	errorCode, bytes := decodeErrorCode(bytes)
-	logs, bytes := decodeLogs(bytes)
+	errorCode, bytes, err := decodeErrorCode(bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	logs, bytes, err := decodeLogs(bytes)
+	if err != nil {
+		return nil, nil, err
+	}
 	rtError := &RuntimeError{Kind: errorCode}
 
-	switch errorCode {
+	hasTemplate, hasTarget, hasFunction, hasMessage, ok := runtimeErrorFields(errorCode)
+	if !ok {
+		return nil, nil, newDecodeError("unknown `error code`")
+	}
+
+	if hasTemplate {
+		if rtError.Template, bytes, err = decodeReceiptAddress(bytes); err != nil {
+			return nil, nil, err
+		}
+	}
+	if hasTarget {
+		if rtError.Target, bytes, err = decodeReceiptAddress(bytes); err != nil {
+			return nil, nil, err
+		}
+	}
+	if hasFunction {
+		if rtError.Function, bytes, err = decodeString(bytes); err != nil {
+			return nil, nil, err
+		}
+	}
+	if hasMessage {
+		if rtError.Message, _, err = decodeString(bytes); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return rtError, logs, nil
+}
+
+// Returns which of the `RuntimeError` fields are encoded for the given `kind` (in that order).
+// When `kind` is unknown - `ok` is set to `false`.
+func runtimeErrorFields(kind RuntimeErrorKind) (hasTemplate, hasTarget, hasFunction, hasMessage, ok bool) {
+	switch kind {
 	case RuntimeErrorKind(OOG):
-		log.Print("OOG")
-		return rtError, logs, nil
+		return false, false, false, false, true
 	case RuntimeErrorKind(TemplateNotFound):
-		log.Print("Template Not Found")
-		template, _ := decodeAddress(bytes)
-		rtError.Template = template
-		return rtError, logs, nil
+		return true, false, false, false, true
 	case RuntimeErrorKind(AccountNotFound):
-		log.Print("Account Not Found")
-		target, _ := decodeAddress(bytes)
-		rtError.Target = target
-		return rtError, logs, nil
+		return false, true, false, false, true
 	case RuntimeErrorKind(CompilationFailed), RuntimeErrorKind(InstantiationFailed):
-		log.Print("...")
-		template, bytes := decodeAddress(bytes)
-		target, bytes := decodeAddress(bytes)
-		msg, _ := decodeString(bytes)
-		rtError.Template = template
-		rtError.Target = target
-		rtError.Message = msg
-		return rtError, logs, nil
-	case RuntimeErrorKind(FuncNotFound):
-		template, bytes := decodeAddress(bytes)
-		target, bytes := decodeAddress(bytes)
-		function, _ := decodeString(bytes)
-		rtError.Template = template
-		rtError.Target = target
-		rtError.Function = function
-		return rtError, logs, nil
+		return true, true, false, true, true
+	case RuntimeErrorKind(FuncNotFound), RuntimeErrorKind(FuncInvalidSignature):
+		return true, true, true, false, true
 	case RuntimeErrorKind(FuncNotCtor):
-		template, bytes := decodeAddress(bytes)
-		function, bytes := decodeString(bytes)
-		rtError.Template = template
-		rtError.Function = function
-		return rtError, logs, nil
+		return true, false, true, false, true
 	case RuntimeErrorKind(FuncFailed), RuntimeErrorKind(FuncNotAllowed):
-		template, bytes := decodeAddress(bytes)
-		target, bytes := decodeAddress(bytes)
-		function, bytes := decodeString(bytes)
-		msg, _ := decodeString(bytes)
-		rtError.Template = template
-		rtError.Target = target
-		rtError.Function = function
-		rtError.Message = msg
-		return rtError, logs, nil
-	case RuntimeErrorKind(FuncInvalidSignature):
-		template, bytes := decodeAddress(bytes)
-		target, bytes := decodeAddress(bytes)
-		function, _ := decodeString(bytes)
-		rtError.Template = template
-		rtError.Target = target
-		rtError.Function = function
-		return rtError, logs, nil
+		return true, true, true, true, true
 	default:
-		panic("Unreachable")
+		return false, false, false, false, false
 	}
 }
 
 func decodeDeployReceipt(bytes []byte) (*DeployReceipt, error) {
-	templateAddr, bytes := decodeAddress(bytes)
-	gas, bytes := decodeGas(bytes)
-	logs, _ := decodeLogs(bytes)
+	templateAddr, bytes, err := decodeReceiptAddress(bytes)
+	if err != nil {
+		return nil, err
+	}
+	gas, bytes, err := decodeReceiptGas(bytes)
+	if err != nil {
+		return nil, err
+	}
+	logs, _, err := decodeLogs(bytes)
+	if err != nil {
+		return nil, err
+	}
 
 	receipt := &DeployReceipt{
 		Success:      true,
@@ -133,12 +183,30 @@ func decodeDeployReceipt(bytes []byte) (*DeployReceipt, error) {
 }
 
 func decodeSpawnReceipt(bytes []byte) (*SpawnReceipt, error) {
-	accountAddr, bytes := decodeAddress(bytes)
-	initState, bytes := decodeState(bytes)
-	returndata, bytes := decodeReturnData(bytes)
-	gas, bytes := decodeGas(bytes)
-	touchedAccounts, bytes := decodeTouchedAccounts(bytes)
-	logs, _ := decodeLogs(bytes)
+	accountAddr, bytes, err := decodeReceiptAddress(bytes)
+	if err != nil {
+		return nil, err
+	}
+	initState, bytes, err := decodeReceiptState(bytes)
+	if err != nil {
+		return nil, err
+	}
+	returndata, bytes, err := decodeReturnData(bytes)
+	if err != nil {
+		return nil, err
+	}
+	gas, bytes, err := decodeReceiptGas(bytes)
+	if err != nil {
+		return nil, err
+	}
+	touchedAccounts, bytes, err := decodeTouchedAccounts(bytes)
+	if err != nil {
+		return nil, err
+	}
+	logs, _, err := decodeLogs(bytes)
+	if err != nil {
+		return nil, err
+	}
 
 	receipt := &SpawnReceipt{
 		Success:         true,
@@ -153,11 +221,26 @@ func decodeSpawnReceipt(bytes []byte) (*SpawnReceipt, error) {
 }
 
 func decodeCallReceipt(bytes []byte) (*CallReceipt, error) {
-	newState, bytes := decodeState(bytes)
-	returndata, bytes := decodeReturnData(bytes)
-	gas, bytes := decodeGas(bytes)
-	touchedAccounts, bytes := decodeTouchedAccounts(bytes)
-	logs, _ := decodeLogs(bytes)
+	newState, bytes, err := decodeReceiptState(bytes)
+	if err != nil {
+		return nil, err
+	}
+	returndata, bytes, err := decodeReturnData(bytes)
+	if err != nil {
+		return nil, err
+	}
+	gas, bytes, err := decodeReceiptGas(bytes)
+	if err != nil {
+		return nil, err
+	}
+	touchedAccounts, bytes, err := decodeTouchedAccounts(bytes)
+	if err != nil {
+		return nil, err
+	}
+	logs, _, err := decodeLogs(bytes)
+	if err != nil {
+		return nil, err
+	}
 
 	receipt := &CallReceipt{
 		Success:         true,
@@ -170,73 +253,308 @@ func decodeCallReceipt(bytes []byte) (*CallReceipt, error) {
 	return receipt, nil
 }
 
-func decodeTouchedAccounts(bytes []byte) ([]Address, []byte) {
+func decodeTouchedAccounts(bytes []byte) ([]Address, []byte, error) {
+	if err := ensureLength(bytes, 2, "touched accounts"); err != nil {
+		return nil, nil, err
+	}
+
 	accounts := []Address{}
 	len := int(binary.BigEndian.Uint16(bytes))
 	bytes = bytes[2:]
 	for i := 0; i < len; i++ {
-		addr, newBytes := decodeAddress(bytes)
+		addr, newBytes, err := decodeReceiptAddress(bytes)
+		if err != nil {
+			return nil, nil, err
+		}
 		bytes = newBytes
 		accounts = append(accounts, addr)
 	}
-	return accounts, bytes
+	return accounts, bytes, nil
 }
 
-func decodeErrorCode(bytes []byte) (RuntimeErrorKind, []byte) {
-	return RuntimeErrorKind(bytes[0]), bytes[1:]
+func decodeErrorCode(bytes []byte) (RuntimeErrorKind, []byte, error) {
+	if err := ensureLength(bytes, 1, "error code"); err != nil {
+		return 0, nil, err
+	}
+	return RuntimeErrorKind(bytes[0]), bytes[1:], nil
 }
 
-func decodeReceiptHeader(bytes []byte) (TxType, bool, []byte) {
-	if len(bytes) < ReceiptHeaderLength {
-		panic("Received a corrupted Receipt")
+func decodeReceiptHeader(bytes []byte) (TxType, ReceiptVersion, bool, []byte, error) {
+	if err := ensureLength(bytes, ReceiptHeaderLength, "header"); err != nil {
+		return 0, 0, false, nil, err
 	}
 
 	txType := TxType(bytes[0])
-	version := int(binary.BigEndian.Uint16(bytes[1:]))
+	version := ReceiptVersion(binary.BigEndian.Uint16(bytes[1:]))
 	success := bytes[3] != 0
 
-	if version != 0 {
-		panic("For now `version` must be zero")
+	return txType, version, success, bytes[ReceiptHeaderLength:], nil
+}
+
+func decodeReceiptAddress(bytes []byte) ([AddressLength]byte, []byte, error) {
+	if err := ensureLength(bytes, AddressLength, "address"); err != nil {
+		return [AddressLength]byte{}, nil, err
 	}
+	addr, bytes := decodeAddress(bytes)
+	return addr, bytes, nil
+}
 
-	return txType, success, bytes[ReceiptHeaderLength:]
+func decodeReceiptState(bytes []byte) (State, []byte, error) {
+	if err := ensureLength(bytes, StateLength, "state"); err != nil {
+		return State{}, nil, err
+	}
+	state, bytes := decodeState(bytes)
+	return state, bytes, nil
 }
 
-func decodeReturnData(bytes []byte) (ReturnData, []byte) {
+func decodeReceiptGas(bytes []byte) (Gas, []byte, error) {
+	if err := ensureLength(bytes, GasLength, "gas"); err != nil {
+		return 0, nil, err
+	}
+	gas, bytes := decodeGas(bytes)
+	return gas, bytes, nil
+}
+
+func decodeReturnData(bytes []byte) (ReturnData, []byte, error) {
+	if err := ensureLength(bytes, 2, "returndata"); err != nil {
+		return nil, nil, err
+	}
+
 	returnsSize := int(binary.BigEndian.Uint16(bytes))
 	offset := 2
 
-	returns := make([]byte, returnsSize)
 	nextOffset := offset + returnsSize
+	if err := ensureLength(bytes, nextOffset, "returndata"); err != nil {
+		return nil, nil, err
+	}
+
+	returns := make([]byte, returnsSize)
 	copy(returns, bytes[offset:nextOffset])
 
-	return returns, bytes[nextOffset:]
+	return returns, bytes[nextOffset:], nil
 }
 
-func decodeLogs(bytes []byte) ([]Log, []byte) {
+func decodeLogs(bytes []byte) ([]Log, []byte, error) {
+	if err := ensureLength(bytes, 1, "logs"); err != nil {
+		return nil, nil, err
+	}
+
 	logsCount := bytes[0]
 	logs := make([]Log, logsCount)
 
 	offset := 1
 	for i := 0; i < int(logsCount); i++ {
+		if err := ensureLength(bytes, offset+2, "log"); err != nil {
+			return nil, nil, err
+		}
 		logLength := int(binary.BigEndian.Uint16(bytes[offset:]))
 		offset += 2
-		log := make([]byte, logLength)
+
 		nextOffset := offset + logLength
+		if err := ensureLength(bytes, nextOffset, "log"); err != nil {
+			return nil, nil, err
+		}
+		log := make([]byte, logLength)
 		copy(log, bytes[offset:nextOffset])
 
 		logs[i] = log
 		offset = nextOffset
 	}
 
-	return logs, bytes[offset:]
+	return logs, bytes[offset:], nil
 }
 
-func decodeString(bytes []byte) (string, []byte) {
-	length := bytes[0]
-	data := make([]byte, length)
+func decodeString(bytes []byte) (string, []byte, error) {
+	if err := ensureLength(bytes, 1, "string"); err != nil {
+		return "", nil, err
+	}
+
+	length := int(bytes[0])
 	nextOffset := 1 + length
+	if err := ensureLength(bytes, nextOffset, "string"); err != nil {
+		return "", nil, err
+	}
+
+	data := make([]byte, length)
 	copy(data, bytes[1:nextOffset])
 
-	return string(data), bytes[nextOffset:]
+	return string(data), bytes[nextOffset:], nil
+}
+
+func ensureLength(bytes []byte, length int, field string) error {
+	if len(bytes) < length {
+		return newDecodeError("not enough bytes for `" + field + "`")
+	}
+	return nil
+}
+
+// Encodes a `DeployReceipt` into its binary form (the same one returned by `SVM`).
+//
+// When `receipt.Success` is `false` only the `Error` and `Logs` are being encoded.
+// Returns `(nil, error)` for a `Version` other than zero.
+func EncodeDeployReceipt(receipt *DeployReceipt) ([]byte, error) {
+	bytes, err := encodeReceiptHeader(DeployType, receipt.Version, receipt.Success)
+	if err != nil {
+		return nil, err
+	}
+	if !receipt.Success {
+		return encodeRuntimeError(bytes, receipt.Error, receipt.Logs)
+	}
+
+	bytes = encodeAddress(bytes, receipt.TemplateAddr)
+	bytes = encodeGas(bytes, receipt.GasUsed)
+	return encodeLogs(bytes, receipt.Logs)
+}
+
+// Encodes a `SpawnReceipt` into its binary form (the same one returned by `SVM`).
+//
+// When `receipt.Success` is `false` only the `Error` and `Logs` are being encoded.
+// Returns `(nil, error)` for a `Version` other than zero.
+func EncodeSpawnReceipt(receipt *SpawnReceipt) ([]byte, error) {
+	bytes, err := encodeReceiptHeader(SpawnType, receipt.Version, receipt.Success)
+	if err != nil {
+		return nil, err
+	}
+	if !receipt.Success {
+		return encodeRuntimeError(bytes, receipt.Error, receipt.Logs)
+	}
+
+	bytes = encodeAddress(bytes, receipt.AccountAddr)
+	bytes = append(bytes, receipt.InitState[:]...)
+	bytes, err = encodeReturnData(bytes, receipt.ReturnData)
+	if err != nil {
+		return nil, err
+	}
+	bytes = encodeGas(bytes, receipt.GasUsed)
+	bytes, err = encodeTouchedAccounts(bytes, receipt.TouchedAccounts)
+	if err != nil {
+		return nil, err
+	}
+	return encodeLogs(bytes, receipt.Logs)
+}
+
+// Encodes a `CallReceipt` into its binary form (the same one returned by `SVM`).
+//
+// When `receipt.Success` is `false` only the `Error` and `Logs` are being encoded.
+// Returns `(nil, error)` for a `Version` other than zero.
+func EncodeCallReceipt(receipt *CallReceipt) ([]byte, error) {
+	bytes, err := encodeReceiptHeader(CallType, receipt.Version, receipt.Success)
+	if err != nil {
+		return nil, err
+	}
+	if !receipt.Success {
+		return encodeRuntimeError(bytes, receipt.Error, receipt.Logs)
+	}
+
+	bytes = append(bytes, receipt.NewState[:]...)
+	bytes, err = encodeReturnData(bytes, receipt.ReturnData)
+	if err != nil {
+		return nil, err
+	}
+	bytes = encodeGas(bytes, receipt.GasUsed)
+	bytes, err = encodeTouchedAccounts(bytes, receipt.TouchedAccounts)
+	if err != nil {
+		return nil, err
+	}
+	return encodeLogs(bytes, receipt.Logs)
+}
+
+// Only the latest `ReceiptVersion` can be encoded.
+func encodeReceiptHeader(txType TxType, version ReceiptVersion, success bool) ([]byte, error) {
+	if version != 0 {
+		return nil, errors.New("unsupported `version` " + strconv.Itoa(int(version)))
+	}
+
+	bytes := []byte{byte(txType)}
+	bytes = encodeVersion(bytes, uint16(version))
+	if success {
+		return append(bytes, 1), nil
+	}
+	return append(bytes, 0), nil
+}
+
+func encodeRuntimeError(bytes []byte, rtError *RuntimeError, logs []Log) ([]byte, error) {
+	if rtError == nil {
+		return nil, errors.New("a failed Receipt must have an `Error`")
+	}
+
+	hasTemplate, hasTarget, hasFunction, hasMessage, ok := runtimeErrorFields(rtError.Kind)
+	if !ok {
+		return nil, errors.New("unknown `RuntimeErrorKind`")
+	}
+
+	bytes = append(bytes, byte(rtError.Kind))
+	bytes, err := encodeLogs(bytes, logs)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasTemplate {
+		bytes = encodeAddress(bytes, rtError.Template)
+	}
+	if hasTarget {
+		bytes = encodeAddress(bytes, rtError.Target)
+	}
+	if hasFunction {
+		if bytes, err = encodeString(bytes, rtError.Function); err != nil {
+			return nil, err
+		}
+	}
+	if hasMessage {
+		if bytes, err = encodeString(bytes, rtError.Message); err != nil {
+			return nil, err
+		}
+	}
+
+	return bytes, nil
+}
+
+func encodeGas(bytes []byte, gas Gas) []byte {
+	var buf [GasLength]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(gas))
+	return append(bytes, buf[:]...)
+}
+
+func encodeReturnData(bytes []byte, returndata []byte) ([]byte, error) {
+	if len(returndata) > 0xFFFF {
+		return nil, errors.New("`returndata` is too long")
+	}
+
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], uint16(len(returndata)))
+	bytes = append(bytes, buf[:]...)
+	return append(bytes, returndata...), nil
+}
+
+func encodeTouchedAccounts(bytes []byte, accounts []Address) ([]byte, error) {
+	if len(accounts) > 0xFFFF {
+		return nil, errors.New("too many touched accounts")
+	}
+
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], uint16(len(accounts)))
+	bytes = append(bytes, buf[:]...)
+	for _, addr := range accounts {
+		bytes = encodeAddress(bytes, addr)
+	}
+	return bytes, nil
+}
+
+func encodeLogs(bytes []byte, logs []Log) ([]byte, error) {
+	if len(logs) > 0xFF {
+		return nil, errors.New("too many logs")
+	}
+
+	bytes = append(bytes, byte(len(logs)))
+	for _, log := range logs {
+		if len(log) > 0xFFFF {
+			return nil, errors.New("log is too long")
+		}
+
+		var buf [2]byte
+		binary.BigEndian.PutUint16(buf[:], uint16(len(log)))
+		bytes = append(bytes, buf[:]...)
+		bytes = append(bytes, log...)
+	}
+	return bytes, nil
 }
@@ -0,0 +1,270 @@
+package svm
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func callReceiptBytes() []byte {
+	state, touched := State{0xAA}, Address{0xBB}
+
+	bytes := []byte{byte(CallType), 0, 0, 1}
+	bytes = append(bytes, state[:]...)
+	bytes = append(bytes, 0, 2, 0x10, 0x00)         // returndata
+	bytes = append(bytes, 0, 0, 0, 0, 0, 0, 0, 100) // gas
+	bytes = append(bytes, 0, 1)                     // touched accounts
+	bytes = append(bytes, touched[:]...)
+	bytes = append(bytes, 1, 0, 3, 'l', 'o', 'g') // logs
+	return bytes
+}
+
+func funcFailedReceiptBytes() []byte {
+	template, target := TemplateAddr{0x01}, Address{0x02}
+
+	bytes := []byte{byte(CallType), 0, 0, 0, byte(FuncFailed)}
+	bytes = append(bytes, 0) // logs
+	bytes = append(bytes, template[:]...)
+	bytes = append(bytes, target[:]...)
+	bytes = append(bytes, 3, 'f', 'o', 'o')
+	bytes = append(bytes, 4, 'o', 'o', 'p', 's')
+	return bytes
+}
+
+func TestDecodeCallReceipt(t *testing.T) {
+	object, err := decodeReceipt(callReceiptBytes())
+	assert.Nil(t, err)
+
+	receipt := object.(*CallReceipt)
+	assert.True(t, receipt.Success)
+	assert.Equal(t, State{0xAA}, receipt.NewState)
+	assert.Equal(t, []byte{0x10, 0x00}, receipt.ReturnData)
+	assert.Equal(t, Gas(100), receipt.GasUsed)
+	assert.Equal(t, []Address{{0xBB}}, receipt.TouchedAccounts)
+	assert.Equal(t, []Log{Log("log")}, receipt.Logs)
+}
+
+func TestDecodeFailedReceipt(t *testing.T) {
+	object, err := decodeReceipt(funcFailedReceiptBytes())
+	assert.Nil(t, err)
+
+	receipt := object.(*CallReceipt)
+	assert.False(t, receipt.Success)
+	assert.Equal(t, &RuntimeError{
+		Kind:     FuncFailed,
+		Template: TemplateAddr{0x01},
+		Target:   Address{0x02},
+		Function: "foo",
+		Message:  "oops",
+	}, receipt.Error)
+}
+
+func TestDecodeReceiptTruncated(t *testing.T) {
+	for _, bytes := range [][]byte{callReceiptBytes(), funcFailedReceiptBytes()} {
+		for n := 0; n < len(bytes); n++ {
+			var decodeErr *DecodeError
+
+			assert.NotPanics(t, func() {
+				_, err := decodeReceipt(bytes[:n])
+				assert.True(t, errors.As(err, &decodeErr), "truncated at %d", n)
+			})
+		}
+	}
+}
+
+func TestDecodeReceiptInvalidHeader(t *testing.T) {
+	var decodeErr *DecodeError
+
+	// unknown `tx type`
+	_, err := decodeReceipt([]byte{0xFF, 0, 0, 1})
+	assert.True(t, errors.As(err, &decodeErr))
+
+	_, err = decodeReceipt([]byte{0xFF, 0, 0, 0, byte(OOG), 0})
+	assert.True(t, errors.As(err, &decodeErr))
+
+	// unknown `version`
+	_, err = decodeReceipt([]byte{byte(CallType), 0, 1, 1})
+	assert.True(t, errors.As(err, &decodeErr))
+
+	// unknown `error code`
+	_, err = decodeReceipt([]byte{byte(CallType), 0, 0, 0, 0xFF, 0})
+	assert.True(t, errors.As(err, &decodeErr))
+}
+
+var allRuntimeErrorKinds = []RuntimeErrorKind{
+	OOG,
+	TemplateNotFound,
+	AccountNotFound,
+	CompilationFailed,
+	InstantiationFailed,
+	FuncNotFound,
+	FuncFailed,
+	FuncNotCtor,
+	FuncNotAllowed,
+	FuncInvalidSignature,
+}
+
+func randomBytes(r *rand.Rand, maxLen int) []byte {
+	bytes := make([]byte, r.Intn(maxLen+1))
+	r.Read(bytes)
+	return bytes
+}
+
+func randomAddress(r *rand.Rand) Address {
+	var addr Address
+	r.Read(addr[:])
+	return addr
+}
+
+func randomLogs(r *rand.Rand) []Log {
+	logs := make([]Log, r.Intn(4))
+	for i := range logs {
+		logs[i] = randomBytes(r, 100)
+	}
+	return logs
+}
+
+func randomTouchedAccounts(r *rand.Rand) []Address {
+	accounts := make([]Address, r.Intn(4))
+	for i := range accounts {
+		accounts[i] = randomAddress(r)
+	}
+	return accounts
+}
+
+// Returns a `RuntimeError` holding only the fields being encoded for `kind`.
+func randomRuntimeError(r *rand.Rand, kind RuntimeErrorKind) *RuntimeError {
+	hasTemplate, hasTarget, hasFunction, hasMessage, _ := runtimeErrorFields(kind)
+
+	rtError := &RuntimeError{Kind: kind}
+	if hasTemplate {
+		rtError.Template = TemplateAddr(randomAddress(r))
+	}
+	if hasTarget {
+		rtError.Target = randomAddress(r)
+	}
+	if hasFunction {
+		rtError.Function = string(randomBytes(r, MaxStringLength))
+	}
+	if hasMessage {
+		rtError.Message = string(randomBytes(r, MaxStringLength))
+	}
+	return rtError
+}
+
+func TestEncodeDecodeReceipts(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		deploy := &DeployReceipt{
+			Success:      true,
+			TemplateAddr: TemplateAddr(randomAddress(r)),
+			GasUsed:      Gas(r.Uint64()),
+			Logs:         randomLogs(r),
+		}
+
+		spawn := &SpawnReceipt{
+			Success:         true,
+			AccountAddr:     randomAddress(r),
+			ReturnData:      randomBytes(r, 300),
+			GasUsed:         Gas(r.Uint64()),
+			Logs:            randomLogs(r),
+			TouchedAccounts: randomTouchedAccounts(r),
+		}
+		r.Read(spawn.InitState[:])
+
+		call := &CallReceipt{
+			Success:         true,
+			ReturnData:      randomBytes(r, 300),
+			GasUsed:         Gas(r.Uint64()),
+			Logs:            randomLogs(r),
+			TouchedAccounts: randomTouchedAccounts(r),
+		}
+		r.Read(call.NewState[:])
+
+		assertReceiptRoundTrip(t, deploy)
+		assertReceiptRoundTrip(t, spawn)
+		assertReceiptRoundTrip(t, call)
+	}
+}
+
+func TestEncodeDecodeFailedReceipts(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+
+	for _, kind := range allRuntimeErrorKinds {
+		for i := 0; i < 10; i++ {
+			assertReceiptRoundTrip(t, &DeployReceipt{Error: randomRuntimeError(r, kind), Logs: randomLogs(r)})
+			assertReceiptRoundTrip(t, &SpawnReceipt{Error: randomRuntimeError(r, kind), Logs: randomLogs(r)})
+			assertReceiptRoundTrip(t, &CallReceipt{Error: randomRuntimeError(r, kind), Logs: randomLogs(r)})
+		}
+	}
+}
+
+func TestEncodeReceiptFromSvmBytes(t *testing.T) {
+	for _, expected := range [][]byte{callReceiptBytes(), funcFailedReceiptBytes()} {
+		object, err := DecodeReceipt(expected)
+		assert.Nil(t, err)
+
+		actual, err := EncodeCallReceipt(object.(*CallReceipt))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestEncodeReceiptInvalid(t *testing.T) {
+	_, err := EncodeCallReceipt(&CallReceipt{Success: false})
+	assert.NotNil(t, err)
+
+	_, err = EncodeCallReceipt(&CallReceipt{Success: false, Error: &RuntimeError{Kind: RuntimeErrorKind(100)}})
+	assert.NotNil(t, err)
+
+	_, err = EncodeCallReceipt(&CallReceipt{Success: true, ReturnData: make([]byte, 0x10000)})
+	assert.NotNil(t, err)
+
+	_, err = EncodeDeployReceipt(&DeployReceipt{Success: true, Logs: make([]Log, 0x100)})
+	assert.NotNil(t, err)
+}
+
+func assertReceiptRoundTrip(t *testing.T, expected interface{}) {
+	var bytes []byte
+	var err error
+
+	switch receipt := expected.(type) {
+	case *DeployReceipt:
+		bytes, err = EncodeDeployReceipt(receipt)
+	case *SpawnReceipt:
+		bytes, err = EncodeSpawnReceipt(receipt)
+	case *CallReceipt:
+		bytes, err = EncodeCallReceipt(receipt)
+	}
+	assert.Nil(t, err)
+
+	actual, err := DecodeReceipt(bytes)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestDecodeReceiptVersion(t *testing.T) {
+	object, err := DecodeReceipt(callReceiptBytes())
+	assert.Nil(t, err)
+	assert.Equal(t, ReceiptVersion(0), object.(*CallReceipt).Version)
+
+	receiptDecoders[1] = func(txType TxType, success bool, bytes []byte) (interface{}, error) {
+		return &CallReceipt{Success: success}, nil
+	}
+	defer delete(receiptDecoders, 1)
+
+	object, err = DecodeReceipt([]byte{byte(CallType), 0, 1, 1})
+	assert.Nil(t, err)
+	assert.Equal(t, &CallReceipt{Version: 1, Success: true}, object)
+
+	_, err = DecodeReceipt([]byte{byte(CallType), 0, 2, 1})
+	assert.EqualError(t, err, "Received a corrupted Receipt: unsupported `version` 2")
+}
+
+func TestEncodeReceiptUnsupportedVersion(t *testing.T) {
+	_, err := EncodeCallReceipt(&CallReceipt{Version: 1, Success: true})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,270 @@
+package svm
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Returned when a `LayerSession` is used after it has been committed or discarded.
+var ErrSessionEnded = errors.New("layer session has already ended")
+
+// Holds the receipt of a single transaction applied within a `LayerSession`.
+//
+// Exactly one of `Deploy`, `Spawn` and `Call` is set (according to `Type`).
+type TxReceipt struct {
+	Type   TxType
+	TxId   TxId
+	Deploy *DeployReceipt
+	Spawn  *SpawnReceipt
+	Call   *CallReceipt
+}
+
+func (r *TxReceipt) Success() bool {
+	switch {
+	case r.Deploy != nil:
+		return r.Deploy.Success
+	case r.Spawn != nil:
+		return r.Spawn.Success
+	case r.Call != nil:
+		return r.Call.Success
+	default:
+		return false
+	}
+}
+
+func (r *TxReceipt) GasUsed() Gas {
+	switch {
+	case r.Deploy != nil:
+		return r.Deploy.GasUsed
+	case r.Spawn != nil:
+		return r.Spawn.GasUsed
+	case r.Call != nil:
+		return r.Call.GasUsed
+	default:
+		return Gas(0)
+	}
+}
+
+// Returns the `RuntimeError` of a failed transaction, and `nil` otherwise.
+func (r *TxReceipt) Err() error {
+	switch {
+	case r.Deploy != nil:
+		return r.Deploy.Err()
+	case r.Spawn != nil:
+		return r.Spawn.Err()
+	case r.Call != nil:
+		return r.Call.Err()
+	default:
+		return nil
+	}
+}
+
+// Holds the outcome of a committed `LayerSession`.
+type LayerResult struct {
+	Layer    Layer
+	State    State
+	Receipts []*TxReceipt
+}
+
+// Drives the execution of a single layer: `Apply` the layer transactions (in order),
+// then either `Commit` or `Discard` them.
+//
+// Once committed (or discarded), the session has ended and any further usage returns `ErrSessionEnded`.
+// A `LayerSession` isn't safe for concurrent usage.
+type LayerSession struct {
+	vm       VM
+	layer    Layer
+	receipts []*TxReceipt
+	ended    bool
+}
+
+// Opens `layer` and returns a `LayerSession` for executing its transactions.
+//
+// See `Open` for the errors returned when `layer` can't be opened.
+func (rt *Runtime) BeginLayer(layer Layer) (*LayerSession, error) {
+	return beginLayer(rt, layer)
+}
+
+// Same as `Runtime.BeginLayer`.
+func (rt *FakeRuntime) BeginLayer(layer Layer) (*LayerSession, error) {
+	return beginLayer(rt, layer)
+}
+
+func beginLayer(vm VM, layer Layer) (*LayerSession, error) {
+	if err := vm.Open(layer); err != nil {
+		return nil, err
+	}
+
+	session := &LayerSession{
+		vm:       vm,
+		layer:    layer,
+		receipts: []*TxReceipt{},
+	}
+	return session, nil
+}
+
+func (s *LayerSession) Layer() Layer {
+	return s.layer
+}
+
+// Executes `tx` within the session layer and records its receipt.
+//
+// A failed transaction still has its receipt recorded (and returned).
+// An `error` is returned only when the transaction couldn't be executed at all,
+// in which case nothing is recorded.
+func (s *LayerSession) Apply(tx *Transaction) (*TxReceipt, error) {
+	if s.ended {
+		return nil, ErrSessionEnded
+	}
+
+	receipt, err := applyTransaction(s.vm, tx, NewContext(s.layer, tx.TxId))
+	if err != nil {
+		return nil, err
+	}
+
+	s.receipts = append(s.receipts, receipt)
+	return receipt, nil
+}
+
+// Commits the session layer and ends the session.
+//
+// On failure, the session remains usable so it can be retried or discarded.
+func (s *LayerSession) Commit() (LayerResult, error) {
+	if s.ended {
+		return LayerResult{}, ErrSessionEnded
+	}
+
+	layer, state, err := s.vm.Commit()
+	if err != nil {
+		return LayerResult{}, err
+	}
+	s.ended = true
+
+	return LayerResult{Layer: layer, State: state, Receipts: s.receipts}, nil
+}
+
+// Discards the changes applied within the session (by rewinding to the previous layer) and ends the session.
+func (s *LayerSession) Discard() error {
+	if s.ended {
+		return ErrSessionEnded
+	}
+	s.ended = true
+
+	_, err := s.vm.Rewind(s.layer - 1)
+	return err
+}
+
+// Dispatches `tx` to `Deploy`, `Spawn` or `Call` according to its `Type`.
+func applyTransaction(vm VM, tx *Transaction, ctx *Context) (*TxReceipt, error) {
+	receipt := &TxReceipt{Type: tx.Type, TxId: tx.TxId}
+
+	var err error
+	switch tx.Type {
+	case DeployType:
+		receipt.Deploy, err = vm.Deploy(tx.Envelope, tx.Message, ctx)
+	case SpawnType:
+		receipt.Spawn, err = vm.Spawn(tx.Envelope, tx.Message, ctx)
+	case CallType:
+		receipt.Call, err = vm.Call(tx.Envelope, tx.Message, ctx)
+	default:
+		return nil, errors.New("unknown transaction `Type` " + strconv.Itoa(int(tx.Type)))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// Decides whether `ExecuteLayer` commits the executed layer or rolls it back.
+type LayerPolicy int
+
+const (
+	// Commits the layer even if some of its transactions have failed.
+	CommitAlways LayerPolicy = 0
+
+	// Rolls the layer back if any of its transactions has failed.
+	RollbackOnFailure LayerPolicy = 1
+)
+
+// Holds the outcome of `ExecuteLayer`.
+//
+// When the layer has been rolled back, `Committed` is `false` and `State` is left empty.
+type LayerExecution struct {
+	LayerResult
+
+	Committed bool
+	GasUsed   Gas
+	Failed    int
+}
+
+// Executes `txs` (in order) under `layer`, and then commits or rolls back the layer according to `policy`.
+//
+// The layer is opened by `ExecuteLayer` itself (see `Open` for the expected `layer`).
+// Returns an `error` (after rolling back the layer) when any of the transactions couldn't be executed at all.
+func (rt *Runtime) ExecuteLayer(layer Layer, txs []*Transaction, policy LayerPolicy) (*LayerExecution, error) {
+	return executeLayer(rt, layer, txs, policy)
+}
+
+// Same as `Runtime.ExecuteLayer`.
+func (rt *FakeRuntime) ExecuteLayer(layer Layer, txs []*Transaction, policy LayerPolicy) (*LayerExecution, error) {
+	return executeLayer(rt, layer, txs, policy)
+}
+
+func executeLayer(vm VM, layer Layer, txs []*Transaction, policy LayerPolicy) (*LayerExecution, error) {
+	session, err := beginLayer(vm, layer)
+	if err != nil {
+		return nil, err
+	}
+
+	execution := &LayerExecution{}
+	for _, tx := range txs {
+		receipt, err := session.Apply(tx)
+		if err != nil {
+			return nil, discardAfter(session, err)
+		}
+
+		execution.GasUsed += receipt.GasUsed()
+		if !receipt.Success() {
+			execution.Failed++
+		}
+	}
+
+	if policy == RollbackOnFailure && execution.Failed > 0 {
+		execution.LayerResult = LayerResult{Layer: layer, Receipts: session.receipts}
+		return execution, session.Discard()
+	}
+
+	result, err := session.Commit()
+	if err != nil {
+		return nil, discardAfter(session, err)
+	}
+
+	execution.LayerResult = result
+	execution.Committed = true
+	return execution, nil
+}
+
+// Returned by `ExecuteLayer` when the layer couldn't be rolled back after an `error`.
+//
+// It unwraps to the original `error`, while `RollbackErr` holds the `error` returned by the rollback itself.
+type RollbackError struct {
+	Err         error
+	RollbackErr error
+}
+
+func (e *RollbackError) Error() string {
+	return e.Err.Error() + " (rolling back the layer has failed: " + e.RollbackErr.Error() + ")"
+}
+
+func (e *RollbackError) Unwrap() error {
+	return e.Err
+}
+
+// Discards `session` following `err`, and returns `err` (wrapped in a `*RollbackError` if the discard has failed).
+func discardAfter(session *LayerSession, err error) error {
+	if rollbackErr := session.Discard(); rollbackErr != nil {
+		return &RollbackError{Err: err, RollbackErr: rollbackErr}
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+package svm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeTransactions(t *testing.T) (*Transaction, *Transaction) {
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000), GasFee(0))
+	deploy := NewTransaction(DeployType, env, readFile(t, "inputs/template_example.svm"), TxId{0x01})
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, Address{0xFF}, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	call := NewTransaction(CallType, env, msg, TxId{0x02})
+
+	return deploy, call
+}
+
+func TestLayerSessionCommit(t *testing.T) {
+	rt := NewFakeRuntime()
+	deploy, call := fakeTransactions(t)
+
+	session, err := rt.BeginLayer(Layer(1))
+	assert.Nil(t, err)
+	assert.Equal(t, Layer(1), session.Layer())
+
+	receipt, err := session.Apply(deploy)
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success())
+	assert.Equal(t, TxId{0x01}, receipt.TxId)
+	assert.NotNil(t, receipt.Deploy)
+
+	receipt, err = session.Apply(call)
+	assert.Nil(t, err)
+	assert.False(t, receipt.Success())
+	assert.True(t, errors.Is(receipt.Err(), ErrAccountNotFound))
+
+	result, err := session.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, Layer(1), result.Layer)
+	assert.Len(t, result.Receipts, 2)
+
+	state, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, state, result.State)
+
+	_, err = session.Apply(deploy)
+	assert.True(t, errors.Is(err, ErrSessionEnded))
+	_, err = session.Commit()
+	assert.True(t, errors.Is(err, ErrSessionEnded))
+	assert.True(t, errors.Is(session.Discard(), ErrSessionEnded))
+
+	_, err = rt.BeginLayer(Layer(1))
+	assert.True(t, errors.Is(err, ErrUnexpectedLayer))
+}
+
+func TestLayerSessionDiscard(t *testing.T) {
+	rt := NewFakeRuntime()
+	deploy, _ := fakeTransactions(t)
+	genesis, err := rt.StateHash()
+	assert.Nil(t, err)
+
+	session, err := rt.BeginLayer(Layer(1))
+	assert.Nil(t, err)
+
+	_, err = session.Apply(deploy)
+	assert.Nil(t, err)
+	assert.Nil(t, session.Discard())
+
+	state, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, genesis, state)
+
+	_, err = session.Apply(deploy)
+	assert.True(t, errors.Is(err, ErrSessionEnded))
+
+	session, err = rt.BeginLayer(Layer(1))
+	assert.Nil(t, err)
+	result, err := session.Commit()
+	assert.Nil(t, err)
+	assert.Empty(t, result.Receipts)
+}
+
+func TestLayerSessionUnknownType(t *testing.T) {
+	rt := NewFakeRuntime()
+	deploy, _ := fakeTransactions(t)
+	deploy.Type = TxType(3)
+
+	session, err := rt.BeginLayer(Layer(1))
+	assert.Nil(t, err)
+
+	_, err = session.Apply(deploy)
+	assert.NotNil(t, err)
+
+	result, err := session.Commit()
+	assert.Nil(t, err)
+	assert.Empty(t, result.Receipts)
+}
+
+func TestExecuteLayerCommit(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.GasUsed = Gas(100)
+	deploy, call := fakeTransactions(t)
+
+	execution, err := rt.ExecuteLayer(Layer(1), []*Transaction{deploy, call}, CommitAlways)
+	assert.Nil(t, err)
+	assert.True(t, execution.Committed)
+	assert.Equal(t, Layer(1), execution.Layer)
+	assert.Equal(t, Gas(100), execution.GasUsed)
+	assert.Equal(t, 1, execution.Failed)
+	assert.Len(t, execution.Receipts, 2)
+	assert.NotNil(t, execution.Receipts[0].Deploy)
+	assert.NotNil(t, execution.Receipts[1].Call)
+
+	state, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, state, execution.State)
+}
+
+func TestExecuteLayerRollback(t *testing.T) {
+	rt := NewFakeRuntime()
+	deploy, call := fakeTransactions(t)
+	genesis, err := rt.StateHash()
+	assert.Nil(t, err)
+
+	execution, err := rt.ExecuteLayer(Layer(1), []*Transaction{deploy, call}, RollbackOnFailure)
+	assert.Nil(t, err)
+	assert.False(t, execution.Committed)
+	assert.Equal(t, 1, execution.Failed)
+	assert.Len(t, execution.Receipts, 2)
+
+	state, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, genesis, state)
+
+	// a failure to execute a transaction always rolls back the layer
+	deploy.Message = []byte{0}
+	_, err = rt.ExecuteLayer(Layer(1), []*Transaction{deploy}, CommitAlways)
+	assert.NotNil(t, err)
+
+	execution, err = rt.ExecuteLayer(Layer(1), []*Transaction{}, RollbackOnFailure)
+	assert.Nil(t, err)
+	assert.True(t, execution.Committed)
+}
+
+// A `FakeRuntime` whose `Rewind` always fails.
+type unrewindableRuntime struct {
+	*FakeRuntime
+}
+
+var errRewindFailed = errors.New("rewind has failed")
+
+func (rt unrewindableRuntime) Rewind(layer Layer) (State, error) {
+	return State{}, errRewindFailed
+}
+
+func TestExecuteLayerRollbackFailure(t *testing.T) {
+	rt := unrewindableRuntime{NewFakeRuntime()}
+	deploy, _ := fakeTransactions(t)
+	deploy.Message = []byte{0}
+
+	_, err := executeLayer(rt, Layer(1), []*Transaction{deploy}, CommitAlways)
+
+	var rollbackErr *RollbackError
+	assert.True(t, errors.As(err, &rollbackErr))
+	assert.Equal(t, errRewindFailed, rollbackErr.RollbackErr)
+	assert.NotNil(t, rollbackErr.Err)
+	assert.Contains(t, err.Error(), "rewind has failed")
+
+	// the original error is still reachable
+	_, decodeErr := DecodeDeployMessage([]byte{0})
+	assert.Equal(t, decodeErr.Error(), errors.Unwrap(err).Error())
+}
@@ -0,0 +1,115 @@
+// +build cgo,!nosvm
+
+package svm
+
+// Estimates the `Gas` required for executing a `Spawn` or a `Call` transaction (according to `txType`).
+//
+// The transaction is executed against the current (uncommitted) state under a `GasLimit` of `EstimateGasLimit`,
+// and all of its changes are discarded afterwards. Neither the uncommitted state nor `StateHash` are affected.
+//
+// # Notes
+//
+// * Discarding the changes requires rewinding to the last committed layer and replaying all the transactions applied since,
+//   so estimating within a busy layer is costly.
+//
+// * When the replay fails, the open layer is left broken: `Commit` (and any further execution) returns a `*BrokenLayerError`
+//   until the `Runtime` is rewinded.
+//
+// * When the transaction fails, both the `GasEstimate` and the failure `RuntimeError` are returned.
+func (rt *Runtime) EstimateGas(txType TxType, env *Envelope, msg []byte, ctx *Context) (*GasEstimate, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	if err := ensureEstimable(txType); err != nil {
+		return nil, err
+	}
+
+	estimateEnv := *env
+	estimateEnv.GasLimit = EstimateGasLimit
+
+	receipt := &TxReceipt{Type: txType, TxId: ctx.TxId}
+	if txType == SpawnType {
+		object, err := rt.simulate(&estimateEnv, msg, ctx, rt.spawnAction)
+		if err != nil {
+			return nil, err
+		}
+		receipt.Spawn = object.(*SpawnReceipt)
+	} else {
+		object, err := rt.simulate(&estimateEnv, msg, ctx, rt.callAction)
+		if err != nil {
+			return nil, err
+		}
+		receipt.Call = object.(*CallReceipt)
+	}
+
+	return newGasEstimate(receipt)
+}
+
+// Executes `action` and discards its changes right afterwards.
+//
+// Returns a `*BrokenLayerError` when the changes couldn't be discarded (or a previous restore has already failed).
+func (rt *Runtime) simulate(env *Envelope, msg []byte, ctx *Context, action svmAction) (interface{}, error) {
+	if err := rt.layers.ensureIntact(); err != nil {
+		return nil, err
+	}
+
+	object, err := runAction(env, msg, ctx, action)
+
+	if restoreErr := rt.restore(len(rt.journal)); restoreErr != nil {
+		return nil, restoreErr
+	}
+	return object, err
+}
+
+// Executes a `Deploy` transaction without applying any of its changes, and returns back its receipt.
+//
+// Same as `Deploy`, but the state is left exactly as it was (see `EstimateGas` for the cost of discarding the changes).
+// No layer has to be open.
+func (rt *Runtime) SimulateDeploy(env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	object, err := rt.simulate(env, msg, ctx, rt.deployAction)
+	if err != nil {
+		return nil, err
+	}
+	return object.(*DeployReceipt), nil
+}
+
+// Executes a `Spawn` transaction without applying any of its changes, and returns back its receipt.
+//
+// Same as `Spawn`, but the state is left exactly as it was (see `EstimateGas` for the cost of discarding the changes).
+// No layer has to be open.
+func (rt *Runtime) SimulateSpawn(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	object, err := rt.simulate(env, msg, ctx, rt.spawnAction)
+	if err != nil {
+		return nil, err
+	}
+	return object.(*SpawnReceipt), nil
+}
+
+// Executes a `Call` transaction without applying any of its changes, and returns back its receipt.
+//
+// Same as `Call`, but the state is left exactly as it was (see `EstimateGas` for the cost of discarding the changes).
+// No layer has to be open.
+func (rt *Runtime) SimulateCall(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	object, err := rt.simulate(env, msg, ctx, rt.callAction)
+	if err != nil {
+		return nil, err
+	}
+	return object.(*CallReceipt), nil
+}
@@ -0,0 +1,64 @@
+package svm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeSimulate(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)}))
+	template := fakeDeploy(t, rt)
+	spawned := fakeSpawn(t, rt, template, "initialize", Amount(10))
+	_, before, err := rt.Commit()
+	assert.Nil(t, err)
+
+	ctx := NewContext(Layer(2), TxId{})
+	env := NewEnvelope(Address{}, Amount(30), TxNonce{}, Gas(1000), GasFee(0))
+
+	template2, err := DecodeDeployMessage(readFile(t, "inputs/template_example.svm"))
+	assert.Nil(t, err)
+	template2.Ctors().Ctors = []string{"init"}
+	deployMsg, err := EncodeDeployMessage(template2)
+	assert.Nil(t, err)
+	deployed, err := rt.SimulateDeploy(env, deployMsg, ctx)
+	assert.Nil(t, err)
+	assert.True(t, deployed.Success)
+	assert.NotEqual(t, template, deployed.TemplateAddr)
+
+	spawnMsg, err := EncodeSpawnMessage(NewSpawnMessage(0, template, "Other Account", "initialize", []byte{}))
+	assert.Nil(t, err)
+	simulated, err := rt.SimulateSpawn(env, spawnMsg, ctx)
+	assert.Nil(t, err)
+	assert.True(t, simulated.Success)
+
+	callMsg, err := EncodeCallMessage(NewCallMessage(0, spawned.AccountAddr, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	called, err := rt.SimulateCall(env, callMsg, ctx)
+	assert.Nil(t, err)
+	assert.True(t, called.Success)
+	assert.Equal(t, []Address{spawned.AccountAddr}, called.TouchedAccounts)
+
+	after, err := rt.StateHash()
+	assert.Nil(t, err)
+	assert.Equal(t, before, after)
+
+	principal, err := rt.GetAccount(Address{})
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(90), principal.Balance)
+
+	account, err := rt.GetAccount(spawned.AccountAddr)
+	assert.Nil(t, err)
+	assert.Equal(t, Amount(10), account.Balance)
+
+	_, err = rt.GetAccount(simulated.AccountAddr)
+	assert.NotNil(t, err)
+
+	// the simulations have left nothing to commit
+	assert.Nil(t, rt.Open(Layer(2)))
+	_, state, err := rt.Commit()
+	assert.Nil(t, err)
+	assert.Equal(t, before, state)
+}
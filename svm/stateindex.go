@@ -0,0 +1,193 @@
+package svm
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// The suffix appended to the `Runtime` path to name the file persisting the state roots index.
+//
+// The file is kept next to the `SVM` storage directory (and not inside it), since that directory is owned by `SVM`.
+const stateIndexFileSuffix = ".state_roots"
+
+const stateIndexRecordLength int = LayerLength + StateLength
+
+// Returned when querying the `State` of a layer that hasn't been committed.
+var ErrLayerNotCommitted = errors.New("layer has not been committed")
+
+// Holds the `Global State Root Hash` of a committed layer.
+type LayerState struct {
+	Layer Layer
+	State State
+}
+
+// Maps each committed layer to its `State`.
+//
+// Layers are committed sequentially, so `states[i]` holds the `State` of layer `first + i`.
+// When backed by a file, each committed layer is appended to it as a `Layer (u64) | State` record,
+// and a `Rewind` truncates the file accordingly.
+type stateIndex struct {
+	first  Layer
+	states []State
+
+	// Empty for an in-memory index.
+	path string
+}
+
+func newStateIndex(layer Layer, state State) *stateIndex {
+	return &stateIndex{first: layer, states: []State{state}}
+}
+
+// Returns the path of the file persisting the state roots index of the `Runtime` stored under `path`.
+func stateIndexPath(path string) string {
+	return filepath.Clean(path) + stateIndexFileSuffix
+}
+
+// Loads the index persisted at `path`, reconciling it with the `SVM` current `layer` and `state`.
+//
+// Records of layers beyond `layer` (e.g. when a crash occurred right after a `Rewind`) are dropped.
+// When the persisted records can't be reconciled, the index restarts from `layer`.
+func loadStateIndex(path string, layer Layer, state State) (*stateIndex, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	index := newStateIndex(layer, state)
+	index.path = path
+
+	bytes, err := ioutil.ReadFile(index.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	records, ok := decodeStateIndex(bytes)
+	if ok && records[0].Layer <= layer {
+		if count := int(layer-records[0].Layer) + 1; count < len(records) {
+			records = records[:count]
+		}
+		if records[len(records)-1].Layer+1 == layer {
+			// the layer committed right before a crash might be missing
+			records = append(records, LayerState{Layer: layer, State: state})
+		}
+
+		if last := records[len(records)-1]; last.Layer == layer && last.State == state {
+			index.first = records[0].Layer
+			index.states = make([]State, len(records))
+			for i, record := range records {
+				index.states[i] = record.State
+			}
+		}
+	}
+
+	return index, index.rewrite()
+}
+
+func decodeStateIndex(bytes []byte) ([]LayerState, bool) {
+	if len(bytes) == 0 || len(bytes)%stateIndexRecordLength != 0 {
+		return nil, false
+	}
+
+	records := make([]LayerState, 0, len(bytes)/stateIndexRecordLength)
+	for off := 0; off < len(bytes); off += stateIndexRecordLength {
+		record := LayerState{Layer: Layer(binary.BigEndian.Uint64(bytes[off:]))}
+		copy(record.State[:], bytes[off+LayerLength:off+stateIndexRecordLength])
+
+		if len(records) > 0 && record.Layer != records[len(records)-1].Layer+1 {
+			return nil, false
+		}
+		records = append(records, record)
+	}
+	return records, true
+}
+
+func encodeStateIndexRecord(bytes []byte, layer Layer, state State) []byte {
+	var buf [LayerLength]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(layer))
+
+	bytes = append(bytes, buf[:]...)
+	return append(bytes, state[:]...)
+}
+
+func (index *stateIndex) last() Layer {
+	return index.first + Layer(len(index.states)-1)
+}
+
+func (index *stateIndex) stateAt(layer Layer) (State, error) {
+	if layer < index.first || layer > index.last() {
+		return State{}, ErrLayerNotCommitted
+	}
+	return index.states[layer-index.first], nil
+}
+
+// Returns the committed layers within `[from, to]` (both inclusive) known to the index.
+func (index *stateIndex) between(from Layer, to Layer) []LayerState {
+	if from < index.first {
+		from = index.first
+	}
+	if to > index.last() {
+		to = index.last()
+	}
+
+	layers := []LayerState{}
+	for layer := from; layer <= to; layer++ {
+		layers = append(layers, LayerState{Layer: layer, State: index.states[layer-index.first]})
+	}
+	return layers
+}
+
+// Records the `state` of the newly committed `layer`.
+func (index *stateIndex) commit(layer Layer, state State) error {
+	if layer != index.last()+1 {
+		// the index has been out of sync with `SVM`, thus it restarts from `layer`
+		index.first = layer
+		index.states = []State{state}
+		return index.rewrite()
+	}
+
+	index.states = append(index.states, state)
+	if index.path == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(index.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(encodeStateIndexRecord(nil, layer, state)); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Drops the layers following `layer`.
+func (index *stateIndex) rewind(layer Layer, state State) error {
+	if layer < index.first {
+		index.first = layer
+		index.states = []State{state}
+		return index.rewrite()
+	}
+
+	if layer <= index.last() {
+		index.states = index.states[:layer-index.first+1]
+	}
+	if index.path == "" {
+		return nil
+	}
+	return os.Truncate(index.path, int64(len(index.states)*stateIndexRecordLength))
+}
+
+func (index *stateIndex) rewrite() error {
+	if index.path == "" {
+		return nil
+	}
+
+	bytes := make([]byte, 0, len(index.states)*stateIndexRecordLength)
+	for i, state := range index.states {
+		bytes = encodeStateIndexRecord(bytes, index.first+Layer(i), state)
+	}
+	return ioutil.WriteFile(index.path, bytes, 0644)
+}
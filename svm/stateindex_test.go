@@ -0,0 +1,73 @@
+package svm
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateIndexInMemory(t *testing.T) {
+	index := newStateIndex(Layer(0), State{0x00})
+
+	assert.Nil(t, index.commit(Layer(1), State{0x01}))
+	assert.Nil(t, index.commit(Layer(2), State{0x02}))
+	assert.Nil(t, index.commit(Layer(3), State{0x03}))
+	assert.Equal(t, Layer(3), index.last())
+
+	state, err := index.stateAt(Layer(2))
+	assert.Nil(t, err)
+	assert.Equal(t, State{0x02}, state)
+
+	_, err = index.stateAt(Layer(4))
+	assert.True(t, errors.Is(err, ErrLayerNotCommitted))
+
+	assert.Equal(t, []LayerState{{Layer(2), State{0x02}}, {Layer(3), State{0x03}}}, index.between(Layer(2), Layer(10)))
+	assert.Empty(t, index.between(Layer(5), Layer(10)))
+
+	assert.Nil(t, index.rewind(Layer(1), State{0x01}))
+	assert.Equal(t, Layer(1), index.last())
+	_, err = index.stateAt(Layer(2))
+	assert.True(t, errors.Is(err, ErrLayerNotCommitted))
+
+	assert.Nil(t, index.commit(Layer(2), State{0x22}))
+	state, err = index.stateAt(Layer(2))
+	assert.Nil(t, err)
+	assert.Equal(t, State{0x22}, state)
+}
+
+func TestStateIndexFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "svm"+stateIndexFileSuffix)
+
+	index, err := loadStateIndex(path, Layer(0), State{0x00})
+	assert.Nil(t, err)
+	assert.Nil(t, index.commit(Layer(1), State{0x01}))
+	assert.Nil(t, index.commit(Layer(2), State{0x02}))
+	assert.Nil(t, index.commit(Layer(3), State{0x03}))
+	assert.Nil(t, index.rewind(Layer(2), State{0x02}))
+
+	bytes, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Len(t, bytes, 3*stateIndexRecordLength)
+
+	reloaded, err := loadStateIndex(path, Layer(2), State{0x02})
+	assert.Nil(t, err)
+	assert.Equal(t, index.between(Layer(0), Layer(2)), reloaded.between(Layer(0), Layer(2)))
+
+	// the last committed layer is missing from the index
+	reloaded, err = loadStateIndex(path, Layer(3), State{0x33})
+	assert.Nil(t, err)
+	state, err := reloaded.stateAt(Layer(3))
+	assert.Nil(t, err)
+	assert.Equal(t, State{0x33}, state)
+	state, err = reloaded.stateAt(Layer(1))
+	assert.Nil(t, err)
+	assert.Equal(t, State{0x01}, state)
+
+	// the index doesn't match the state of `SVM`
+	reloaded, err = loadStateIndex(path, Layer(1), State{0xFF})
+	assert.Nil(t, err)
+	assert.Equal(t, []LayerState{{Layer(1), State{0xFF}}}, reloaded.between(Layer(0), Layer(10)))
+}
@@ -0,0 +1,558 @@
+package svm
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+type SectionKind uint16
+type CodeKind uint16
+type GasMode uint64
+type LayoutKind uint16
+
+// A `Section Kind` enum
+const (
+	CodeSectionKind   SectionKind = 1
+	DataSectionKind   SectionKind = 2
+	CtorsSectionKind  SectionKind = 3
+	SchemaSectionKind SectionKind = 4
+	ApiSectionKind    SectionKind = 5
+	HeaderSectionKind SectionKind = 6
+	DeploySectionKind SectionKind = 7
+)
+
+const (
+	WasmCode CodeKind = 1
+)
+
+const (
+	GasModeFixed    GasMode = 1
+	GasModeMetering GasMode = 2
+)
+
+const (
+	FixedLayout LayoutKind = 1
+)
+
+// Set under `CodeSection.Flags` when the `Template` code is executable.
+const ExecFlag uint64 = 1
+
+// * Two bytes for `section kind`
+// * Four bytes for `section byte size`
+const SectionHeaderLength = 2 + 4
+
+// A single `Section` of a `Deploy Message`.
+type Section interface {
+	Kind() SectionKind
+}
+
+// Holds the `Template` Wasm code alongside its execution parameters.
+type CodeSection struct {
+	CodeKind   CodeKind
+	Flags      uint64
+	GasMode    GasMode
+	SvmVersion uint32
+	Code       []byte
+}
+
+// Holds the storage layouts of a `Template`.
+type DataSection struct {
+	Layouts []DataLayout
+}
+
+// Holds a `Fixed` storage layout.
+//
+// Variables are numbered sequentially starting with `FirstVarId`,
+// and `VarLengths[i]` is the byte-size of the variable `FirstVarId + i`.
+type DataLayout struct {
+	Kind       LayoutKind
+	FirstVarId uint32
+	VarLengths []uint16
+}
+
+// Holds the names of the `Template` constructors.
+type CtorsSection struct {
+	Ctors []string
+}
+
+// Holds the `Template` storage schema. It's kept in its raw binary form.
+type SchemaSection struct {
+	Raw []byte
+}
+
+// Holds the `Template` API. It's kept in its raw binary form.
+type ApiSection struct {
+	Raw []byte
+}
+
+// Holds the `Template` human-readable metadata.
+type HeaderSection struct {
+	CodeVersion uint32
+	Name        string
+	Desc        string
+}
+
+// Holds the deployment information of a `Template`.
+// This `Section` is added by `SVM` itself when a `Template` gets deployed.
+type DeploySection struct {
+	TxId     TxId
+	Layer    Layer
+	Deployer Address
+	Template TemplateAddr
+}
+
+func (*CodeSection) Kind() SectionKind   { return CodeSectionKind }
+func (*DataSection) Kind() SectionKind   { return DataSectionKind }
+func (*CtorsSection) Kind() SectionKind  { return CtorsSectionKind }
+func (*SchemaSection) Kind() SectionKind { return SchemaSectionKind }
+func (*ApiSection) Kind() SectionKind    { return ApiSectionKind }
+func (*HeaderSection) Kind() SectionKind { return HeaderSectionKind }
+func (*DeploySection) Kind() SectionKind { return DeploySectionKind }
+
+// Holds the `Sections` of a `Deploy Message` in their encoding order.
+type DeployMessage struct {
+	Sections []Section
+}
+
+func NewDeployMessage(sections ...Section) *DeployMessage {
+	return &DeployMessage{Sections: sections}
+}
+
+// Returns the `Section` of the given `kind`, or `nil` when there is no such `Section`.
+func (msg *DeployMessage) Section(kind SectionKind) Section {
+	for _, section := range msg.Sections {
+		if section.Kind() == kind {
+			return section
+		}
+	}
+	return nil
+}
+
+func (msg *DeployMessage) Code() *CodeSection {
+	section, _ := msg.Section(CodeSectionKind).(*CodeSection)
+	return section
+}
+
+func (msg *DeployMessage) Data() *DataSection {
+	section, _ := msg.Section(DataSectionKind).(*DataSection)
+	return section
+}
+
+func (msg *DeployMessage) Ctors() *CtorsSection {
+	section, _ := msg.Section(CtorsSectionKind).(*CtorsSection)
+	return section
+}
+
+func (msg *DeployMessage) Schema() *SchemaSection {
+	section, _ := msg.Section(SchemaSectionKind).(*SchemaSection)
+	return section
+}
+
+func (msg *DeployMessage) Api() *ApiSection {
+	section, _ := msg.Section(ApiSectionKind).(*ApiSection)
+	return section
+}
+
+func (msg *DeployMessage) Header() *HeaderSection {
+	section, _ := msg.Section(HeaderSectionKind).(*HeaderSection)
+	return section
+}
+
+// Encodes a binary `Deploy Message` (a.k.a a `Template`).
+//
+// ```text
+//  +------------+--------------+--------------+-------------+-----+
+//  |            |              |              |             |     |
+//  | #Sections  | Section Kind | Section Size |   Section   | ... |
+//  |   (u16)    |    (u16)     |    (u32)     |   (Blob)    |     |
+//  |            |              |              |             |     |
+//  +------------+--------------+--------------+-------------+-----+
+// ```
+//
+// The `Sections` are encoded in the order they appear under `msg.Sections`.
+// Returns `(nil, error)` when a `Section` can't be encoded or appears more than once.
+func EncodeDeployMessage(msg *DeployMessage) ([]byte, error) {
+	if len(msg.Sections) > 0xFFFF {
+		return nil, errors.New("too many `Sections`")
+	}
+
+	bytes := make([]byte, 2)
+	binary.BigEndian.PutUint16(bytes, uint16(len(msg.Sections)))
+
+	seen := make(map[SectionKind]bool)
+	for _, section := range msg.Sections {
+		if seen[section.Kind()] {
+			return nil, errors.New("duplicate `Section`")
+		}
+		seen[section.Kind()] = true
+
+		body, err := encodeSection(section)
+		if err != nil {
+			return nil, err
+		}
+
+		var header [SectionHeaderLength]byte
+		binary.BigEndian.PutUint16(header[:2], uint16(section.Kind()))
+		binary.BigEndian.PutUint32(header[2:], uint32(len(body)))
+
+		bytes = append(bytes, header[:]...)
+		bytes = append(bytes, body...)
+	}
+
+	return bytes, nil
+}
+
+// Decodes a binary `Deploy Message` (a.k.a a `Template`) into its `Sections`.
+//
+// Returns `(nil, error)` when `bytes` isn't a well-formed `Deploy Message`.
+func DecodeDeployMessage(bytes []byte) (*DeployMessage, error) {
+	if len(bytes) < 2 {
+		return nil, errCorruptedTemplate
+	}
+
+	count := int(binary.BigEndian.Uint16(bytes))
+	bytes = bytes[2:]
+
+	msg := &DeployMessage{Sections: make([]Section, 0, count)}
+	seen := make(map[SectionKind]bool)
+
+	for i := 0; i < count; i++ {
+		if len(bytes) < SectionHeaderLength {
+			return nil, errCorruptedTemplate
+		}
+
+		kind := SectionKind(binary.BigEndian.Uint16(bytes))
+		size := int(binary.BigEndian.Uint32(bytes[2:]))
+		bytes = bytes[SectionHeaderLength:]
+
+		if size > len(bytes) {
+			return nil, errCorruptedTemplate
+		}
+		if seen[kind] {
+			return nil, errors.New("duplicate `Section`")
+		}
+		seen[kind] = true
+
+		section, err := decodeSection(kind, bytes[:size])
+		if err != nil {
+			return nil, withDecodeSubject(err, "Template")
+		}
+
+		msg.Sections = append(msg.Sections, section)
+		bytes = bytes[size:]
+	}
+
+	if len(bytes) != 0 {
+		return nil, errCorruptedTemplate
+	}
+
+	return msg, nil
+}
+
+var errCorruptedTemplate = errors.New("Received a corrupted Template")
+
+func encodeSection(section Section) ([]byte, error) {
+	switch s := section.(type) {
+	case *CodeSection:
+		return encodeCodeSection(s), nil
+	case *DataSection:
+		return encodeDataSection(s)
+	case *CtorsSection:
+		return encodeCtorsSection(s)
+	case *SchemaSection:
+		return s.Raw, nil
+	case *ApiSection:
+		return s.Raw, nil
+	case *HeaderSection:
+		return encodeHeaderSection(s)
+	case *DeploySection:
+		return encodeDeploySection(s), nil
+	default:
+		return nil, errors.New("unsupported `Section`")
+	}
+}
+
+func decodeSection(kind SectionKind, bytes []byte) (Section, error) {
+	var section Section
+	var err error
+
+	switch kind {
+	case CodeSectionKind:
+		section, bytes, err = decodeCodeSection(bytes)
+	case DataSectionKind:
+		section, bytes, err = decodeDataSection(bytes)
+	case CtorsSectionKind:
+		section, bytes, err = decodeCtorsSection(bytes)
+	case SchemaSectionKind:
+		section, bytes = &SchemaSection{Raw: copyBytes(bytes)}, nil
+	case ApiSectionKind:
+		section, bytes = &ApiSection{Raw: copyBytes(bytes)}, nil
+	case HeaderSectionKind:
+		section, bytes, err = decodeHeaderSection(bytes)
+	case DeploySectionKind:
+		section, bytes, err = decodeDeploySection(bytes)
+	default:
+		return nil, errors.New("unknown `Section` kind")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes) != 0 {
+		return nil, errCorruptedTemplate
+	}
+	return section, nil
+}
+
+// ```text
+//  +-------------+-----------+-------------+---------------+---------------+-------------+
+//  |             |           |             |               |               |             |
+//  |  Code Kind  |   Flags   |  Gas Mode   |  SVM Version  |  Code Length  |    Code     |
+//  |   (u16)     |   (u64)   |   (u64)     |    (u32)      |    (u32)      |   (Blob)    |
+//  |             |           |             |               |               |             |
+//  +-------------+-----------+-------------+---------------+---------------+-------------+
+// ```
+func encodeCodeSection(section *CodeSection) []byte {
+	bytes := make([]byte, 26, 26+len(section.Code))
+
+	binary.BigEndian.PutUint16(bytes[0:], uint16(section.CodeKind))
+	binary.BigEndian.PutUint64(bytes[2:], section.Flags)
+	binary.BigEndian.PutUint64(bytes[10:], uint64(section.GasMode))
+	binary.BigEndian.PutUint32(bytes[18:], section.SvmVersion)
+	binary.BigEndian.PutUint32(bytes[22:], uint32(len(section.Code)))
+
+	return append(bytes, section.Code...)
+}
+
+func decodeCodeSection(bytes []byte) (*CodeSection, []byte, error) {
+	if len(bytes) < 26 {
+		return nil, nil, errCorruptedTemplate
+	}
+
+	section := &CodeSection{
+		CodeKind:   CodeKind(binary.BigEndian.Uint16(bytes[0:])),
+		Flags:      binary.BigEndian.Uint64(bytes[2:]),
+		GasMode:    GasMode(binary.BigEndian.Uint64(bytes[10:])),
+		SvmVersion: binary.BigEndian.Uint32(bytes[18:]),
+	}
+	length := int(binary.BigEndian.Uint32(bytes[22:]))
+	bytes = bytes[26:]
+
+	if length > len(bytes) {
+		return nil, nil, errCorruptedTemplate
+	}
+	section.Code = copyBytes(bytes[:length])
+
+	return section, bytes[length:], nil
+}
+
+// ```text
+//  +-------------+---------------+---------+------------------+------------------+-----+
+//  |             |               |         |                  |                  |     |
+//  |  #Layouts   |  Layout Kind  |  #Vars  |  First Var Id    |  Var #0 Length   | ... |
+//  |   (u16)     |    (u16)      |  (u16)  |     (u32)        |     (u16)        |     |
+//  |             |               |         |                  |                  |     |
+//  +-------------+---------------+---------+------------------+------------------+-----+
+// ```
+func encodeDataSection(section *DataSection) ([]byte, error) {
+	if len(section.Layouts) > 0xFFFF {
+		return nil, errors.New("too many `Layouts`")
+	}
+
+	bytes := make([]byte, 2)
+	binary.BigEndian.PutUint16(bytes, uint16(len(section.Layouts)))
+
+	for _, layout := range section.Layouts {
+		if layout.Kind != FixedLayout {
+			return nil, errors.New("unsupported `Layout` kind")
+		}
+		if len(layout.VarLengths) > 0xFFFF {
+			return nil, errors.New("too many `Layout` variables")
+		}
+
+		var header [8]byte
+		binary.BigEndian.PutUint16(header[0:], uint16(layout.Kind))
+		binary.BigEndian.PutUint16(header[2:], uint16(len(layout.VarLengths)))
+		binary.BigEndian.PutUint32(header[4:], layout.FirstVarId)
+		bytes = append(bytes, header[:]...)
+
+		for _, length := range layout.VarLengths {
+			var buf [2]byte
+			binary.BigEndian.PutUint16(buf[:], length)
+			bytes = append(bytes, buf[:]...)
+		}
+	}
+
+	return bytes, nil
+}
+
+func decodeDataSection(bytes []byte) (*DataSection, []byte, error) {
+	if len(bytes) < 2 {
+		return nil, nil, errCorruptedTemplate
+	}
+
+	count := int(binary.BigEndian.Uint16(bytes))
+	bytes = bytes[2:]
+	section := &DataSection{Layouts: make([]DataLayout, 0, count)}
+
+	for i := 0; i < count; i++ {
+		if len(bytes) < 8 {
+			return nil, nil, errCorruptedTemplate
+		}
+
+		layout := DataLayout{Kind: LayoutKind(binary.BigEndian.Uint16(bytes))}
+		if layout.Kind != FixedLayout {
+			return nil, nil, errors.New("unsupported `Layout` kind")
+		}
+
+		nvars := int(binary.BigEndian.Uint16(bytes[2:]))
+		layout.FirstVarId = binary.BigEndian.Uint32(bytes[4:])
+		bytes = bytes[8:]
+
+		if len(bytes) < 2*nvars {
+			return nil, nil, errCorruptedTemplate
+		}
+
+		layout.VarLengths = make([]uint16, nvars)
+		for j := 0; j < nvars; j++ {
+			layout.VarLengths[j] = binary.BigEndian.Uint16(bytes[2*j:])
+		}
+		bytes = bytes[2*nvars:]
+
+		section.Layouts = append(section.Layouts, layout)
+	}
+
+	return section, bytes, nil
+}
+
+// ```text
+//  +----------+-------------------+-----+
+//  |          |                   |     |
+//  |  #Ctors  |  Ctor #0 Name     | ... |
+//  |   (u8)   |    (String)       |     |
+//  |          |                   |     |
+//  +----------+-------------------+-----+
+// ```
+func encodeCtorsSection(section *CtorsSection) ([]byte, error) {
+	if len(section.Ctors) > 0xFF {
+		return nil, errors.New("too many `Ctors`")
+	}
+
+	bytes := []byte{byte(len(section.Ctors))}
+	for _, ctor := range section.Ctors {
+		var err error
+		if bytes, err = encodeString(bytes, ctor); err != nil {
+			return nil, err
+		}
+	}
+
+	return bytes, nil
+}
+
+func decodeCtorsSection(bytes []byte) (*CtorsSection, []byte, error) {
+	if len(bytes) < 1 {
+		return nil, nil, errCorruptedTemplate
+	}
+
+	count := int(bytes[0])
+	bytes = bytes[1:]
+	section := &CtorsSection{Ctors: make([]string, 0, count)}
+
+	for i := 0; i < count; i++ {
+		ctor, rest, err := decodeString(bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		section.Ctors = append(section.Ctors, ctor)
+		bytes = rest
+	}
+
+	return section, bytes, nil
+}
+
+// ```text
+//  +----------------+------------+-------------------+
+//  |                |            |                   |
+//  |  Code Version  |    Name    |    Description    |
+//  |     (u32)      |  (String)  |     (String)      |
+//  |                |            |                   |
+//  +----------------+------------+-------------------+
+// ```
+func encodeHeaderSection(section *HeaderSection) ([]byte, error) {
+	bytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(bytes, section.CodeVersion)
+
+	bytes, err := encodeString(bytes, section.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeString(bytes, section.Desc)
+}
+
+func decodeHeaderSection(bytes []byte) (*HeaderSection, []byte, error) {
+	if len(bytes) < 4 {
+		return nil, nil, errCorruptedTemplate
+	}
+
+	section := &HeaderSection{CodeVersion: binary.BigEndian.Uint32(bytes)}
+
+	name, bytes, err := decodeString(bytes[4:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	desc, bytes, err := decodeString(bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	section.Name = name
+	section.Desc = desc
+	return section, bytes, nil
+}
+
+// ```text
+//  +-------------+-------------+--------------+--------------+
+//  |             |             |              |              |
+//  |    Tx Id    |    Layer    |   Deployer   |   Template   |
+//  |   (Blob)    |    (u64)    |  (Address)   |  (Address)   |
+//  |             |             |              |              |
+//  |  32 bytes   |   8 bytes   |   20 bytes   |   20 bytes   |
+//  |             |             |              |              |
+//  +-------------+-------------+--------------+--------------+
+// ```
+func encodeDeploySection(section *DeploySection) []byte {
+	bytes := make([]byte, 0, TxIdLength+LayerLength+2*AddressLength)
+	bytes = append(bytes, section.TxId[:]...)
+
+	var layer [LayerLength]byte
+	binary.BigEndian.PutUint64(layer[:], uint64(section.Layer))
+	bytes = append(bytes, layer[:]...)
+
+	bytes = encodeAddress(bytes, section.Deployer)
+	return encodeAddress(bytes, section.Template)
+}
+
+func decodeDeploySection(bytes []byte) (*DeploySection, []byte, error) {
+	if len(bytes) < TxIdLength+LayerLength+2*AddressLength {
+		return nil, nil, errCorruptedTemplate
+	}
+
+	txId, bytes := decodeTxId(bytes)
+	layer, bytes := decodeLayer(bytes)
+	deployer, bytes := decodeAddress(bytes)
+	template, bytes := decodeAddress(bytes)
+
+	section := &DeploySection{
+		TxId:     txId,
+		Layer:    layer,
+		Deployer: deployer,
+		Template: template,
+	}
+	return section, bytes, nil
+}
+
+func copyBytes(bytes []byte) []byte {
+	return append([]byte{}, bytes...)
+}
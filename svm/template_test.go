@@ -0,0 +1,83 @@
+package svm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeDeployMessage(t *testing.T) {
+	bytes := readFile(t, "inputs/template_example.svm")
+
+	msg, err := DecodeDeployMessage(bytes)
+	assert.Nil(t, err)
+	assert.Len(t, msg.Sections, 3)
+
+	code := msg.Code()
+	assert.NotNil(t, code)
+	assert.Equal(t, WasmCode, code.CodeKind)
+	assert.Equal(t, ExecFlag, code.Flags)
+	assert.Equal(t, GasModeFixed, code.GasMode)
+	assert.Equal(t, []byte("\x00asm"), code.Code[:4])
+
+	ctors := msg.Ctors()
+	assert.NotNil(t, ctors)
+	assert.Equal(t, []string{"initialize"}, ctors.Ctors)
+
+	data := msg.Data()
+	assert.NotNil(t, data)
+	assert.Equal(t, []DataLayout{{Kind: FixedLayout, FirstVarId: 0, VarLengths: []uint16{20}}}, data.Layouts)
+
+	assert.Nil(t, msg.Header())
+	assert.Nil(t, msg.Schema())
+	assert.Nil(t, msg.Api())
+}
+
+func TestEncodeDecodeDeployMessage(t *testing.T) {
+	bytes := readFile(t, "inputs/template_example.svm")
+
+	msg, err := DecodeDeployMessage(bytes)
+	assert.Nil(t, err)
+
+	actual, err := EncodeDeployMessage(msg)
+	assert.Nil(t, err)
+	assert.Equal(t, bytes, actual)
+}
+
+func TestEncodeDecodeDeployMessageAllSections(t *testing.T) {
+	expected := NewDeployMessage(
+		&HeaderSection{CodeVersion: 3, Name: "My Template", Desc: "A Template"},
+		&CodeSection{CodeKind: WasmCode, Flags: ExecFlag, GasMode: GasModeFixed, SvmVersion: 1, Code: []byte{0x00, 0x61, 0x73, 0x6d}},
+		&DataSection{Layouts: []DataLayout{{Kind: FixedLayout, FirstVarId: 0, VarLengths: []uint16{20, 8, 1}}}},
+		&CtorsSection{Ctors: []string{"initialize", "init_other"}},
+		&SchemaSection{Raw: []byte{1, 2, 3}},
+		&ApiSection{Raw: []byte{4, 5}},
+		&DeploySection{TxId: TxId{0xAA}, Layer: Layer(7), Deployer: Address{0xBB}, Template: TemplateAddr{0xCC}},
+	)
+
+	bytes, err := EncodeDeployMessage(expected)
+	assert.Nil(t, err)
+
+	actual, err := DecodeDeployMessage(bytes)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestEncodeDeployMessageDuplicateSection(t *testing.T) {
+	msg := NewDeployMessage(&CtorsSection{}, &CtorsSection{})
+
+	_, err := EncodeDeployMessage(msg)
+	assert.NotNil(t, err)
+}
+
+func TestDecodeDeployMessageCorrupted(t *testing.T) {
+	bytes := readFile(t, "inputs/template_example.svm")
+
+	for _, n := range []int{0, 1, 2, 7, 100, len(bytes) - 1} {
+		_, err := DecodeDeployMessage(bytes[:n])
+		assert.NotNil(t, err)
+	}
+
+	_, err := DecodeDeployMessage(append(bytes, 0))
+	assert.NotNil(t, err)
+}
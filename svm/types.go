@@ -1,6 +1,9 @@
 package svm
 
-import "unsafe"
+import (
+	"sync"
+	"unsafe"
+)
 
 const (
 	AddressLength  int = 20
@@ -13,6 +16,11 @@ const (
 	LayerLength    int = 8
 	EnvelopeLength int = AddressLength + AmountLength + TxNonceLength + GasLength + GasFeeLength
 	ContextLength  int = LayerLength + TxIdLength
+	VersionLength  int = 2
+
+	// Both `String` and `Blob` fields of a `Message` are prefixed with a single length byte.
+	MaxStringLength int = 255
+	MaxBlobLength   int = 255
 )
 
 // Declaring types aliases used throughout the project.
@@ -26,11 +34,63 @@ type Gas uint64
 type GasFee uint64
 type Layer uint64
 type ReturnData []byte
+type ReceiptVersion uint16
 type Log []byte
 
 // `Runtime` wraps the raw-Runtime returned by SVM C-API
+//
+// A `Runtime` is safe for concurrent usage:
+//
+// * Operations calling into `SVM` (e.g `Deploy`, `Call`, `Commit`, `GetAccount` and the `Validate*` methods) are serialized.
+// * Queries answered without `SVM` (`StateAt`, `LastCommitted` and `CommittedLayers`) may run concurrently.
+//
+// Concurrent validations should use a `RuntimePool` instead.
+//
+// Note that a sequence of operations (for example, a `LayerSession`) isn't atomic,
+// so a layer should still be driven by a single goroutine.
 type Runtime struct {
+	mu  sync.RWMutex
 	raw unsafe.Pointer
+
+	layers layerTracker
+	index  *stateIndex
+
+	// The state mutations applied since the last `Commit` / `Rewind`.
+	// Replayed when the uncommitted state has to be restored (see `EstimateGas`).
+	journal []func() error
+
+	// Called by the background execution of the `*Context` variants right after the transaction completes.
+	// Only set by tests, for emulating a transaction still running once its `context.Context` is done.
+	execHook func()
+
+	metrics Metrics
+	logger  Logger
+}
+
+// `VM` is the set of operations offered by an `SVM` runtime.
+//
+// It's implemented by `*Runtime` (backed by the SVM C-API) and by `*FakeRuntime`
+// (a pure-Go, in-memory fake intended for unit tests).
+type VM interface {
+	ValidateDeploy(msg []byte) (bool, error)
+	ValidateSpawn(msg []byte) (bool, error)
+	ValidateCall(msg []byte) (bool, error)
+
+	Deploy(env *Envelope, msg []byte, ctx *Context) (*DeployReceipt, error)
+	Spawn(env *Envelope, msg []byte, ctx *Context) (*SpawnReceipt, error)
+	Call(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error)
+	Verify(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error)
+
+	Open(layer Layer) error
+	Commit() (Layer, State, error)
+	Rewind(layer Layer) (State, error)
+	StateHash() (State, error)
+
+	GetAccount(addr Address) (Account, error)
+	CreateAccount(account Account) error
+	IncreaseBalance(addr Address, amount Amount) error
+
+	Destroy()
 }
 
 // Holds the currently executed `Node Context`.
@@ -65,6 +125,42 @@ type Envelope struct {
 	GasFee    GasFee
 }
 
+func NewEnvelope(principal Address, amount Amount, txNonce TxNonce, gasLimit Gas, gasFee GasFee) *Envelope {
+	return &Envelope{
+		Principal: principal,
+		Amount:    amount,
+		TxNonce:   txNonce,
+		GasLimit:  gasLimit,
+		GasFee:    gasFee,
+	}
+}
+
+func NewContext(layer Layer, txId TxId) *Context {
+	return &Context{
+		Layer: layer,
+		TxId:  txId,
+	}
+}
+
+// Holds a transaction to be executed within a layer.
+//
+// The `Message` is kept in its binary form, and is interpreted according to `Type`.
+type Transaction struct {
+	Type     TxType
+	Envelope *Envelope
+	Message  []byte
+	TxId     TxId
+}
+
+func NewTransaction(txType TxType, env *Envelope, msg []byte, txId TxId) *Transaction {
+	return &Transaction{
+		Type:     txType,
+		Envelope: env,
+		Message:  msg,
+		TxId:     txId,
+	}
+}
+
 // Holds an `Account` basic information.
 type Account struct {
 	Addr    Address
@@ -74,6 +170,7 @@ type Account struct {
 
 // Holds the data returned after executing a `Deploy` transaction.
 type DeployReceipt struct {
+	Version      ReceiptVersion
 	Success      bool
 	Error        *RuntimeError
 	TemplateAddr TemplateAddr
@@ -83,6 +180,7 @@ type DeployReceipt struct {
 
 // Holds the data returned after executing a `Spawn` transaction.
 type SpawnReceipt struct {
+	Version         ReceiptVersion
 	Success         bool
 	Error           *RuntimeError
 	AccountAddr     Address
@@ -95,6 +193,7 @@ type SpawnReceipt struct {
 
 // Holds the data returned after executing a `Call` transaction.
 type CallReceipt struct {
+	Version         ReceiptVersion
 	Success         bool
 	Error           *RuntimeError
 	NewState        State
@@ -103,3 +202,62 @@ type CallReceipt struct {
 	Logs            []Log
 	TouchedAccounts []Address
 }
+
+// Holds the fields of a `Call Message` (see `EncodeCallMessage`).
+type CallMessage struct {
+	Version    uint16
+	Target     Address
+	FuncName   string
+	VerifyData []byte
+	CallData   []byte
+}
+
+// Holds the fields of a `Spawn Message` (see `EncodeSpawnMessage`).
+type SpawnMessage struct {
+	Version  uint16
+	Template TemplateAddr
+	Name     string
+	CtorName string
+	CallData []byte
+}
+
+// Decodes the `ABI` encoded `ReturnData` into typed `Value`s.
+func (data ReturnData) Values() ([]Value, error) {
+	return DecodeValues(data)
+}
+
+// Decodes the data returned by the constructor into typed `Value`s.
+//
+// Returns `(nil, error)` when the `ReturnData` is malformed.
+func (r *SpawnReceipt) Returns() ([]Value, error) {
+	return ReturnData(r.ReturnData).Values()
+}
+
+// Decodes the data returned by the called function into typed `Value`s.
+//
+// Returns `(nil, error)` when the `ReturnData` is malformed.
+func (r *CallReceipt) Returns() ([]Value, error) {
+	return ReturnData(r.ReturnData).Values()
+}
+
+// Returns the `RuntimeError` of a failed transaction, and `nil` otherwise.
+func (r *DeployReceipt) Err() error {
+	return receiptErr(r.Success, r.Error)
+}
+
+// Returns the `RuntimeError` of a failed transaction, and `nil` otherwise.
+func (r *SpawnReceipt) Err() error {
+	return receiptErr(r.Success, r.Error)
+}
+
+// Returns the `RuntimeError` of a failed transaction, and `nil` otherwise.
+func (r *CallReceipt) Err() error {
+	return receiptErr(r.Success, r.Error)
+}
+
+func receiptErr(success bool, rtError *RuntimeError) error {
+	if success || rtError == nil {
+		return nil
+	}
+	return rtError
+}
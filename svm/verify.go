@@ -0,0 +1,81 @@
+package svm
+
+// Holds the outcome of `ExecuteCall`, which consists of two stages: `Verify` and then `Call`.
+//
+// `Call` is `nil` when the `Verify` stage has failed (in that case the `Call` stage is skipped).
+type ExecuteCallReceipt struct {
+	Verify *CallReceipt
+	Call   *CallReceipt
+}
+
+// Returns whether both stages have succeeded.
+func (r *ExecuteCallReceipt) Success() bool {
+	return r.Verify.Success && r.Call != nil && r.Call.Success
+}
+
+// Returns the `Gas` used by both stages.
+func (r *ExecuteCallReceipt) GasUsed() Gas {
+	gas := r.Verify.GasUsed
+	if r.Call != nil {
+		gas += r.Call.GasUsed
+	}
+	return gas
+}
+
+// Returns the logs emitted by both stages (the `Verify` logs come first).
+func (r *ExecuteCallReceipt) Logs() []Log {
+	logs := append([]Log{}, r.Verify.Logs...)
+	if r.Call != nil {
+		logs = append(logs, r.Call.Logs...)
+	}
+	return logs
+}
+
+// Returns the `RuntimeError` of the failed stage, and `nil` when both stages have succeeded.
+func (r *ExecuteCallReceipt) Err() error {
+	if err := r.Verify.Err(); err != nil {
+		return err
+	}
+	if r.Call != nil {
+		return r.Call.Err()
+	}
+	return nil
+}
+
+// Same as `Runtime.ExecuteCall`.
+func (rt *FakeRuntime) ExecuteCall(env *Envelope, msg []byte, ctx *Context) (*ExecuteCallReceipt, error) {
+	if err := rt.lock(); err != nil {
+		return nil, err
+	}
+	defer rt.mu.Unlock()
+
+	return executeCall(rt.verifyLocked, rt.callLocked, env, msg, ctx)
+}
+
+// Executes a single stage of `ExecuteCall` (while the caller holds the `VM`).
+type callStage func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error)
+
+// Runs the `verify` stage and, only if it succeeds, the `call` stage with the `Gas` left.
+//
+// When the `call` stage couldn't be executed, the receipt holding the `Verify` stage is returned along with the `error`.
+func executeCall(verify callStage, call callStage, env *Envelope, msg []byte, ctx *Context) (*ExecuteCallReceipt, error) {
+	verifyReceipt, err := verify(env, msg, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &ExecuteCallReceipt{Verify: verifyReceipt}
+	if !verifyReceipt.Success {
+		return receipt, nil
+	}
+
+	callEnv := *env
+	if verifyReceipt.GasUsed < env.GasLimit {
+		callEnv.GasLimit = env.GasLimit - verifyReceipt.GasUsed
+	} else {
+		callEnv.GasLimit = Gas(0)
+	}
+
+	receipt.Call, err = call(&callEnv, msg, ctx)
+	return receipt, err
+}
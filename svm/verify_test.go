@@ -0,0 +1,121 @@
+package svm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteCall(t *testing.T) {
+	rt := NewFakeRuntime()
+	rt.GasUsed = Gas(100)
+	assert.Nil(t, rt.Open(Layer(1)))
+	assert.Nil(t, rt.CreateAccount(Account{Addr: Address{}, Balance: Amount(100)}))
+
+	template := fakeDeploy(t, rt)
+	spawned := fakeSpawn(t, rt, template, "initialize", Amount(0))
+	assert.True(t, spawned.Success)
+
+	var callEnv *Envelope
+	rt.CallHook = func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+		callEnv = env
+		return &CallReceipt{Success: true, GasUsed: Gas(300), Logs: []Log{[]byte("call")}}, nil
+	}
+	rt.VerifyHook = func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+		return &CallReceipt{Success: true, GasUsed: Gas(200), Logs: []Log{[]byte("verify")}}, nil
+	}
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, spawned.AccountAddr, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(10), TxNonce{}, Gas(1000), GasFee(0))
+
+	receipt, err := rt.ExecuteCall(env, msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success())
+	assert.Nil(t, receipt.Err())
+	assert.Equal(t, Gas(500), receipt.GasUsed())
+	assert.Equal(t, []Log{[]byte("verify"), []byte("call")}, receipt.Logs())
+	assert.Equal(t, Gas(800), callEnv.GasLimit)
+	assert.Equal(t, Gas(1000), env.GasLimit)
+}
+
+func TestExecuteCallVerifyFailed(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+
+	called := false
+	rt.CallHook = func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+		called = true
+		return &CallReceipt{Success: true}, nil
+	}
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, Address{0xFF}, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000), GasFee(0))
+
+	receipt, err := rt.ExecuteCall(env, msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+	assert.False(t, called)
+	assert.Nil(t, receipt.Call)
+	assert.False(t, receipt.Success())
+	assert.True(t, errors.Is(receipt.Err(), ErrAccountNotFound))
+}
+
+func TestExecuteCallCallError(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+
+	rt.VerifyHook = func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+		return &CallReceipt{Success: true, GasUsed: Gas(200)}, nil
+	}
+	rt.CallHook = func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+		return nil, errors.New("call failed")
+	}
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, Address{0xFF}, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000), GasFee(0))
+
+	// the receipt of the `Verify` stage is kept
+	receipt, err := rt.ExecuteCall(env, msg, NewContext(Layer(1), TxId{}))
+	assert.EqualError(t, err, "call failed")
+	assert.NotNil(t, receipt)
+	assert.True(t, receipt.Verify.Success)
+	assert.Nil(t, receipt.Call)
+	assert.False(t, receipt.Success())
+	assert.Equal(t, Gas(200), receipt.GasUsed())
+}
+
+func TestExecuteCallHoldsRuntime(t *testing.T) {
+	rt := NewFakeRuntime()
+	assert.Nil(t, rt.Open(Layer(1)))
+
+	interleaved := make(chan error)
+	rt.VerifyHook = func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+		go func() {
+			interleaved <- rt.CreateAccount(Account{Addr: Address{0x01}})
+		}()
+		time.Sleep(10 * time.Millisecond)
+		return &CallReceipt{Success: true}, nil
+	}
+
+	exists := false
+	rt.CallHook = func(env *Envelope, msg []byte, ctx *Context) (*CallReceipt, error) {
+		_, exists = rt.state.accounts[Address{0x01}]
+		return &CallReceipt{Success: true}, nil
+	}
+
+	msg, err := EncodeCallMessage(NewCallMessage(0, Address{0xFF}, "store_addr", nil, nil))
+	assert.Nil(t, err)
+	env := NewEnvelope(Address{}, Amount(0), TxNonce{}, Gas(1000), GasFee(0))
+
+	receipt, err := rt.ExecuteCall(env, msg, NewContext(Layer(1), TxId{}))
+	assert.Nil(t, err)
+	assert.True(t, receipt.Success())
+
+	// the concurrent operation has waited for both stages to complete
+	assert.Nil(t, <-interleaved)
+	assert.False(t, exists)
+}